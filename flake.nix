@@ -14,14 +14,16 @@
       event-template = pkgs.buildGoModule {
         pname = "event-template";
         version = "0.1.0";
-        src = self + /tools/event-template;
+        src = self + /tools;
+        modRoot = "event-template";
         vendorHash = null;
       };
 
       generate-index = pkgs.buildGoModule {
               pname = "generate-index";
               version = "0.1.0";
-              src = self + /tools/generate-index;
+              src = self + /tools;
+              modRoot = "generate-index";
               vendorHash = null;
       };
       generate-go-types = pkgs.buildGoModule {
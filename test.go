@@ -1,42 +1,241 @@
+// Code generated by generate-go-types. DO NOT EDIT.
+
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
-type Items struct {
-	ProductId  string      `json:"product_id" zog:"productid"`
-	Quantity   int64       `json:"quantity" zog:"quantity"`
+type DataItems struct {
+	// Unique identifier for the product
+	//
+	// Constraints: pattern ^prod_[a-zA-Z0-9]+$.
+	ProductId string `json:"product_id" zog:"productid"`
+	// Number of items ordered
+	//
+	// Constraints: minimum 1.
+	Quantity int64 `json:"quantity" zog:"quantity"`
+	// Total price for this item (quantity * unit_price)
+	//
+	// Constraints: exclusive minimum 0.
 	TotalPrice json.Number `json:"total_price" zog:"totalprice"`
-	UnitPrice  json.Number `json:"unit_price" zog:"unitprice"`
+	// Price per unit
+	//
+	// Constraints: exclusive minimum 0.
+	UnitPrice json.Number `json:"unit_price" zog:"unitprice"`
+}
+
+// Current status of the order
+type DataOrderStatus string
+
+const (
+	DataOrderStatusCreated DataOrderStatus = "created"
+)
+
+// Valid reports whether v is one of the DataOrderStatus values.
+func (v DataOrderStatus) Valid() bool {
+	switch v {
+	case DataOrderStatusCreated:
+		return true
+	}
+	return false
 }
 
-type ShippingAddress struct {
-	City       string `json:"city" zog:"city"`
-	Country    string `json:"country" zog:"country"`
+func (v DataOrderStatus) String() string {
+	return string(v)
+}
+
+func (v *DataOrderStatus) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if !DataOrderStatus(raw).Valid() {
+		return fmt.Errorf("invalid DataOrderStatus %q", raw)
+	}
+	*v = DataOrderStatus(raw)
+	return nil
+}
+
+type DataShippingAddress struct {
+	// City name
+	City string `json:"city" zog:"city"`
+	// Country name
+	Country string `json:"country" zog:"country"`
+	// Postal or ZIP code
 	PostalCode string `json:"postal_code" zog:"postalcode"`
-	State      string `json:"state" zog:"state"`
-	Street     string `json:"street" zog:"street"`
+	// State or province
+	//
+	// Constraints: min length 2.
+	State string `json:"state" zog:"state"`
+	// Street address
+	Street string `json:"street" zog:"street"`
+}
+
+// Method of shipping
+type DataShippingMethod string
+
+const (
+	DataShippingMethodStandard  DataShippingMethod = "standard"
+	DataShippingMethodExpress   DataShippingMethod = "express"
+	DataShippingMethodOvernight DataShippingMethod = "overnight"
+)
+
+// Valid reports whether v is one of the DataShippingMethod values.
+func (v DataShippingMethod) Valid() bool {
+	switch v {
+	case DataShippingMethodStandard, DataShippingMethodExpress, DataShippingMethodOvernight:
+		return true
+	}
+	return false
+}
+
+func (v DataShippingMethod) String() string {
+	return string(v)
+}
+
+func (v *DataShippingMethod) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if !DataShippingMethod(raw).Valid() {
+		return fmt.Errorf("invalid DataShippingMethod %q", raw)
+	}
+	*v = DataShippingMethod(raw)
+	return nil
 }
 
 type Data struct {
-	CreatedAt       string          `json:"created_at" zog:"createdat"`
-	CustomerId      string          `json:"customer_id" zog:"customerid"`
-	Items           []Items         `json:"items" zog:"items"`
-	OrderId         string          `json:"order_id" zog:"orderid"`
-	OrderStatus     string          `json:"order_status" zog:"orderstatus"`
-	ShippingAddress ShippingAddress `json:"shipping_address" zog:"shippingaddress"`
-	ShippingMethod  string          `json:"shipping_method,omitempty" zog:"shippingmethod"`
-	TotalAmount     json.Number     `json:"total_amount" zog:"totalamount"`
+	// Time when the order was created
+	CreatedAt time.Time `json:"created_at" zog:"createdat"`
+	// Unique identifier for the customer
+	//
+	// Constraints: pattern ^cust_[a-zA-Z0-9]+$.
+	CustomerId string `json:"customer_id" zog:"customerid"`
+	// List of items in the order
+	//
+	// Constraints: min items 1.
+	Items []DataItems `json:"items" zog:"items"`
+	// Unique identifier for the order
+	//
+	// Constraints: pattern ^ord_[a-zA-Z0-9]+$.
+	OrderId string `json:"order_id" zog:"orderid"`
+	// Current status of the order
+	OrderStatus     DataOrderStatus     `json:"order_status" zog:"orderstatus"`
+	ShippingAddress DataShippingAddress `json:"shipping_address" zog:"shippingaddress"`
+	// Method of shipping
+	ShippingMethod DataShippingMethod `json:"shipping_method,omitempty" zog:"shippingmethod"`
+	// Total amount of the order
+	//
+	// Constraints: exclusive minimum 0.
+	TotalAmount json.Number `json:"total_amount" zog:"totalamount"`
+}
+
+// NewData returns a new Data with its defaults set.
+func NewData() Data {
+	v := Data{}
+	v.ApplyDefaults()
+	return v
+}
+
+// ApplyDefaults sets unset optional fields to their default values and
+// constant fields to their only value, e.g. after decoding.
+func (v *Data) ApplyDefaults() {
+	if v.OrderStatus == "" {
+		v.OrderStatus = "created"
+	}
+}
+
+// Type of the event
+type MetadataEventType string
+
+const (
+	MetadataEventTypeOrderCreated MetadataEventType = "order.created"
+)
+
+// Valid reports whether v is one of the MetadataEventType values.
+func (v MetadataEventType) Valid() bool {
+	switch v {
+	case MetadataEventTypeOrderCreated:
+		return true
+	}
+	return false
+}
+
+func (v MetadataEventType) String() string {
+	return string(v)
+}
+
+func (v *MetadataEventType) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if !MetadataEventType(raw).Valid() {
+		return fmt.Errorf("invalid MetadataEventType %q", raw)
+	}
+	*v = MetadataEventType(raw)
+	return nil
 }
 
 type Metadata struct {
-	EventId       string `json:"event_id" zog:"eventid"`
-	EventType     string `json:"event_type" zog:"eventtype"`
+	// Unique identifier for the event
+	//
+	// Constraints: pattern ^evt_[a-zA-Z0-9]+$.
+	EventId string `json:"event_id" zog:"eventid"`
+	// Type of the event
+	EventType MetadataEventType `json:"event_type" zog:"eventtype"`
+	// Version of the schema definition
+	//
+	// Constraints: pattern ^\d+\.\d+$.
 	SchemaVersion string `json:"schema_version" zog:"schemaversion"`
-	Timestamp     string `json:"timestamp" zog:"timestamp"`
-	Version       int64  `json:"version" zog:"version"`
+	// Time when the event was created
+	Timestamp time.Time `json:"timestamp" zog:"timestamp"`
+	// Version of the event payload
+	//
+	// Constraints: minimum 1.
+	Version int64 `json:"version" zog:"version"`
+}
+
+// NewMetadata returns a new Metadata with its defaults set.
+func NewMetadata() Metadata {
+	v := Metadata{}
+	v.ApplyDefaults()
+	return v
+}
+
+// ApplyDefaults sets unset optional fields to their default values and
+// constant fields to their only value, e.g. after decoding.
+func (v *Metadata) ApplyDefaults() {
+	if v.EventType == "" {
+		v.EventType = "order.created"
+	}
 }
 
+// OrderCreatedEvent
+//
+// Schema for an event representing a newly created order
 type Root struct {
 	Data     Data     `json:"data" zog:"data"`
 	Metadata Metadata `json:"metadata" zog:"metadata"`
 }
+
+// NewRoot returns a new Root with its defaults set.
+func NewRoot() Root {
+	v := Root{
+		Data:     NewData(),
+		Metadata: NewMetadata(),
+	}
+	v.ApplyDefaults()
+	return v
+}
+
+// ApplyDefaults sets unset optional fields to their default values and
+// constant fields to their only value, e.g. after decoding.
+func (v *Root) ApplyDefaults() {
+	v.Data.ApplyDefaults()
+	v.Metadata.ApplyDefaults()
+}
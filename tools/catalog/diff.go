@@ -0,0 +1,91 @@
+package catalog
+
+import "strings"
+
+// diffLine is a line of a diff: kept (' '), removed ('-') or added ('+').
+type diffLine struct {
+	Op   string
+	Text string
+}
+
+// diffLines returns the line diff of a and b, from their longest common
+// subsequence of lines.
+func diffLines(a, b string) []diffLine {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var diff []diffLine
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			diff = append(diff, diffLine{" ", x[i]})
+			i++
+			j++
+		case j < len(y) && (i == len(x) || lcs[i][j+1] >= lcs[i+1][j]):
+			diff = append(diff, diffLine{"+", y[j]})
+			j++
+		default:
+			diff = append(diff, diffLine{"-", x[i]})
+			i++
+		}
+	}
+	return diff
+}
+
+// fieldChange is a field added, removed or changed between two versions.
+type fieldChange struct {
+	// Change is "added", "removed" or "changed".
+	Change string
+	Path   string
+	// From is the type and requiredness of a removed or changed field, and
+	// To that of an added or changed field.
+	From, To string
+}
+
+// diffFields returns the fields added, removed or changed from old to new, in
+// the order of new and then of the removed fields of old.
+func diffFields(old, new []Field) []fieldChange {
+	describe := func(f Field) string {
+		if f.Required {
+			return f.Type + ", required"
+		}
+		return f.Type
+	}
+	before := make(map[string]Field, len(old))
+	for _, f := range old {
+		before[f.Path] = f
+	}
+	after := make(map[string]bool, len(new))
+	var changes []fieldChange
+	for _, f := range new {
+		after[f.Path] = true
+		o, ok := before[f.Path]
+		switch {
+		case !ok:
+			changes = append(changes, fieldChange{Change: "added", Path: f.Path, To: describe(f)})
+		case describe(o) != describe(f):
+			changes = append(changes, fieldChange{Change: "changed", Path: f.Path, From: describe(o), To: describe(f)})
+		}
+	}
+	for _, f := range old {
+		if !after[f.Path] {
+			changes = append(changes, fieldChange{Change: "removed", Path: f.Path, From: describe(f)})
+		}
+	}
+	return changes
+}
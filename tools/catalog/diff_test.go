@@ -0,0 +1,28 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc\n", "a\nc\nd\n")
+	want := []diffLine{{" ", "a"}, {"-", "b"}, {" ", "c"}, {"+", "d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	old := []Field{{Path: "id", Type: "string", Required: true}, {Path: "total", Type: "number"}, {Path: "note", Type: "string"}}
+	new := []Field{{Path: "id", Type: "string", Required: true}, {Path: "total", Type: "number", Required: true}, {Path: "coupon", Type: "string"}}
+	got := diffFields(old, new)
+	want := []fieldChange{
+		{Change: "changed", Path: "total", From: "number", To: "number, required"},
+		{Change: "added", Path: "coupon", To: "string"},
+		{Change: "removed", Path: "note", From: "string"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,350 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Field is a field of an event's payload, as listed in its schema.
+type Field struct {
+	// Path is the path of the field in the payload, e.g.
+	// "data.items[].product_id", or for protobuf schemas the message and
+	// field, e.g. "OrderCreatedData.order_id".
+	Path        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// maxFieldDepth bounds how deep Fields descends into nested and recursive
+// schemas.
+const maxFieldDepth = 8
+
+// Fields returns the fields of the payload described by the schema, in the
+// order they are declared. Schemas which cannot be parsed have none.
+func (s Schema) Fields() []Field {
+	switch s.Format {
+	case FormatJSONSchema:
+		v, err := decodeOrdered(json.NewDecoder(bytes.NewReader(s.content)))
+		root, ok := v.(map[string]interface{})
+		if err != nil || !ok {
+			return nil
+		}
+		var fields []Field
+		jsonSchemaFields(&fields, root, root, "", 0)
+		return fields
+	case FormatAvro:
+		var root interface{}
+		if json.Unmarshal(s.content, &root) != nil {
+			return nil
+		}
+		var fields []Field
+		avroFields(&fields, root, "", 0)
+		return fields
+	case FormatProtobuf:
+		return protoFields(string(s.content))
+	}
+	return nil
+}
+
+// resolveJSONRef returns the schema a local $ref like "#/$defs/Item" points
+// to, and the name of its definition.
+func resolveJSONRef(root map[string]interface{}, ref string) (map[string]interface{}, string) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, ref
+	}
+	var node interface{} = root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, ref
+		}
+		node = obj[strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")]
+	}
+	schema, _ := node.(map[string]interface{})
+	return schema, ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// jsonSchemaType describes the type of a JSON schema, e.g. "string
+// (date-time)", "Item[]" or "enum: a, b".
+func jsonSchemaType(root, schema map[string]interface{}) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		_, name := resolveJSONRef(root, ref)
+		return name
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		var values []string
+		for _, v := range enum {
+			b, _ := json.Marshal(v)
+			values = append(values, string(b))
+		}
+		return "enum: " + strings.Join(values, ", ")
+	}
+	if c, ok := schema["const"]; ok {
+		b, _ := json.Marshal(c)
+		return "const: " + string(b)
+	}
+	var t string
+	switch v := schema["type"].(type) {
+	case string:
+		t = v
+	case []interface{}:
+		var types []string
+		for _, s := range v {
+			types = append(types, fmt.Sprint(s))
+		}
+		t = strings.Join(types, " | ")
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if variants, ok := schema[key].([]interface{}); ok {
+			var types []string
+			for _, v := range variants {
+				if sub, ok := v.(map[string]interface{}); ok {
+					types = append(types, jsonSchemaType(root, sub))
+				}
+			}
+			t = strings.Join(types, " | ")
+		}
+	}
+	if t == "array" {
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			t = jsonSchemaType(root, items) + "[]"
+		}
+	}
+	if format, ok := schema["format"].(string); ok {
+		t += " (" + format + ")"
+	}
+	return t
+}
+
+// jsonSchemaFields appends the fields of the properties of a JSON schema,
+// and of the objects nested in them.
+func jsonSchemaFields(fields *[]Field, root, schema map[string]interface{}, prefix string, depth int) {
+	if depth > maxFieldDepth {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if target, _ := resolveJSONRef(root, ref); target != nil {
+			schema = target
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		jsonSchemaFields(fields, root, items, prefix+"[]", depth+1)
+		return
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if sub, ok := sub.(map[string]interface{}); ok {
+				jsonSchemaFields(fields, root, sub, prefix, depth+1)
+			}
+		}
+	}
+	props, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if names, ok := schema["required"].([]interface{}); ok {
+		for _, name := range names {
+			required[fmt.Sprint(name)] = true
+		}
+	}
+	for _, name := range orderedKeys(schema, "properties") {
+		prop, ok := props[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		description, _ := prop["description"].(string)
+		if description == "" {
+			if target, _ := resolveJSONRef(root, fmt.Sprint(prop["$ref"])); target != nil {
+				description, _ = target["description"].(string)
+			}
+		}
+		*fields = append(*fields, Field{
+			Path:        path,
+			Type:        jsonSchemaType(root, prop),
+			Required:    required[name],
+			Description: description,
+		})
+		jsonSchemaFields(fields, root, prop, path, depth+1)
+	}
+}
+
+// orderedKeys returns the keys of the object at key in schema in the order
+// they were declared, as recorded by decodeOrdered, or else sorted.
+func orderedKeys(schema map[string]interface{}, key string) []string {
+	obj, _ := schema[key].(map[string]interface{})
+	if order, ok := obj[keyOrder].([]string); ok {
+		return order
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keyOrder is the key under which decodeOrdered records the order of the
+// keys of an object.
+const keyOrder = "\x00order"
+
+// decodeOrdered decodes JSON like json.Unmarshal into interface{}, but
+// records the order of the keys of each object under keyOrder.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := make(map[string]interface{})
+		var order []string
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj[key.(string)] = value
+			order = append(order, key.(string))
+		}
+		obj[keyOrder] = order
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		var arr []interface{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// avroType describes an Avro type, e.g. "string", "Item[]" or "null | int".
+func avroType(t interface{}) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case []interface{}:
+		var types []string
+		for _, u := range v {
+			types = append(types, avroType(u))
+		}
+		return strings.Join(types, " | ")
+	case map[string]interface{}:
+		switch v["type"] {
+		case "array":
+			return avroType(v["items"]) + "[]"
+		case "map":
+			return "map<string, " + avroType(v["values"]) + ">"
+		case "record", "enum", "fixed":
+			return fmt.Sprint(v["name"])
+		}
+		if logical, ok := v["logicalType"].(string); ok {
+			return avroType(v["type"]) + " (" + logical + ")"
+		}
+		return avroType(v["type"])
+	}
+	return ""
+}
+
+// avroFields appends the fields of an Avro record, and of the records nested
+// in them.
+func avroFields(fields *[]Field, t interface{}, prefix string, depth int) {
+	if depth > maxFieldDepth {
+		return
+	}
+	switch v := t.(type) {
+	case []interface{}:
+		for _, u := range v {
+			avroFields(fields, u, prefix, depth+1)
+		}
+	case map[string]interface{}:
+		switch v["type"] {
+		case "array":
+			avroFields(fields, v["items"], prefix+"[]", depth+1)
+		case "map":
+			avroFields(fields, v["values"], prefix+"{}", depth+1)
+		case "record":
+			list, _ := v["fields"].([]interface{})
+			for _, f := range list {
+				field, ok := f.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				path := fmt.Sprint(field["name"])
+				if prefix != "" {
+					path = prefix + "." + path
+				}
+				doc, _ := field["doc"].(string)
+				_, optional := field["default"]
+				*fields = append(*fields, Field{Path: path, Type: avroType(field["type"]), Required: !optional, Description: doc})
+				avroFields(fields, field["type"], path, depth+1)
+			}
+		}
+	}
+}
+
+// protoField matches a field of a protobuf message and its comment.
+var protoField = regexp.MustCompile(`^\s*(optional\s+|repeated\s+)?([\w.<>, ]+?)\s+(\w+)\s*=\s*\d+`)
+
+// protoFields returns the fields of the messages of a protobuf schema, by
+// message. No field is required, as in proto3.
+func protoFields(proto string) []Field {
+	var fields []Field
+	var messages []string
+	var comment []string
+	for _, line := range strings.Split(proto, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			comment = append(comment, strings.TrimSpace(strings.TrimPrefix(trimmed, "//")))
+			continue
+		case strings.HasPrefix(trimmed, "message ") || strings.HasPrefix(trimmed, "enum ") || strings.HasPrefix(trimmed, "oneof "):
+			name := strings.Fields(trimmed)[1]
+			if strings.HasPrefix(trimmed, "oneof ") {
+				// the fields of a oneof belong to its message
+				name = ""
+			}
+			messages = append(messages, strings.TrimSuffix(name, "{"))
+		case strings.HasPrefix(trimmed, "}"):
+			if len(messages) > 0 {
+				messages = messages[:len(messages)-1]
+			}
+		case len(messages) > 0 && protoField.MatchString(trimmed):
+			m := protoField.FindStringSubmatch(trimmed)
+			var path []string
+			for _, name := range messages {
+				if name != "" {
+					path = append(path, name)
+				}
+			}
+			t := strings.TrimSpace(m[2])
+			if strings.TrimSpace(m[1]) == "repeated" {
+				t += "[]"
+			}
+			fields = append(fields, Field{
+				Path:        strings.Join(append(path, m[3]), "."),
+				Type:        t,
+				Description: strings.Join(comment, " "),
+			})
+		}
+		comment = nil
+	}
+	return fields
+}
@@ -0,0 +1,83 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema Schema
+		want   []Field
+	}{
+		{
+			name: "json",
+			schema: Schema{Format: FormatJSONSchema, content: []byte(`{
+  "required": ["id"],
+  "properties": {
+    "id": {"type": "string", "description": "The ID"},
+    "items": {"type": "array", "items": {"$ref": "#/$defs/Item"}},
+    "at": {"type": ["string", "null"], "format": "date-time"}
+  },
+  "$defs": {
+    "Item": {"required": ["sku"], "properties": {"sku": {"enum": ["a", "b"]}}}
+  }
+}`)},
+			want: []Field{
+				{Path: "id", Type: "string", Required: true, Description: "The ID"},
+				{Path: "items", Type: "Item[]"},
+				{Path: "items[].sku", Type: `enum: "a", "b"`, Required: true},
+				{Path: "at", Type: "string | null (date-time)"},
+			},
+		},
+		{
+			name: "avro",
+			schema: Schema{Format: FormatAvro, content: []byte(`{"type": "record", "name": "Event", "fields": [
+  {"name": "id", "type": "string", "doc": "The ID"},
+  {"name": "note", "type": ["null", "string"], "default": null},
+  {"name": "item", "type": {"type": "record", "name": "Item", "fields": [{"name": "sku", "type": "string"}]}}
+]}`)},
+			want: []Field{
+				{Path: "id", Type: "string", Required: true, Description: "The ID"},
+				{Path: "note", Type: "null | string"},
+				{Path: "item", Type: "Item", Required: true},
+				{Path: "item.sku", Type: "string", Required: true},
+			},
+		},
+		{
+			name: "proto",
+			schema: Schema{Format: FormatProtobuf, content: []byte(`syntax = "proto3";
+
+message Event {
+  // The ID
+  string id = 1;
+  repeated Item items = 2;
+  message Item {
+    optional string sku = 1;
+  }
+  oneof kind {
+    int64 count = 3;
+  }
+}
+`)},
+			want: []Field{
+				{Path: "Event.id", Type: "string", Description: "The ID"},
+				{Path: "Event.items", Type: "Item[]"},
+				{Path: "Event.Item.sku", Type: "string"},
+				{Path: "Event.count", Type: "int64"},
+			},
+		},
+		{
+			name:   "invalid",
+			schema: Schema{Format: FormatJSONSchema, content: []byte(`{`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schema.Fields(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
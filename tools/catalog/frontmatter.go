@@ -0,0 +1,236 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFrontMatter is returned for docs whose front matter cannot be
+// parsed.
+var ErrInvalidFrontMatter = errors.New("invalid front matter")
+
+// The statuses of event versions which are no longer active.
+const (
+	StatusDeprecated = "deprecated"
+	StatusRetired    = "retired"
+	StatusRenamed    = "renamed"
+)
+
+// Metadata is the YAML front matter at the top of the docs of an event
+// version, between "---" lines.
+type Metadata struct {
+	Subject     string
+	Version     int
+	Description string
+	Owner       string
+	Producers   []string
+	Consumers   []string
+	Tags        []string
+
+	// Status is StatusDeprecated, StatusRetired or StatusRenamed, or empty
+	// for active versions.
+	Status string
+	// Sunset is the date a deprecated version will be retired, e.g.
+	// "2025-06-30".
+	Sunset string
+	// ReplacedBy is what replaces a deprecated version, e.g.
+	// "order.{order_id}.placed v1".
+	ReplacedBy string
+	// Retired is the date a version was retired.
+	Retired string
+	// RenamedTo is the subject a renamed event moved to.
+	RenamedTo string
+
+	// extra holds the lines of the keys the tools do not know, which are
+	// written back as they were.
+	extra []string
+}
+
+// ParseDoc splits the docs of an event version into their front matter and
+// the markdown after it. Docs without front matter have empty Metadata.
+//
+// Only the YAML the tools write is understood: "key: value" lines, with
+// plain, single or double quoted values, and lists either as "[a, b]" or as
+// "- item" lines below their key.
+func ParseDoc(doc string) (Metadata, string, error) {
+	var m Metadata
+	if !strings.HasPrefix(doc, "---\n") {
+		return m, doc, nil
+	}
+	end := strings.Index(doc[len("---\n"):], "\n---\n")
+	if end < 0 {
+		return m, doc, fmt.Errorf("%w: no closing \"---\" line", ErrInvalidFrontMatter)
+	}
+	front := doc[len("---\n") : len("---\n")+end]
+	body := doc[len("---\n")+end+len("\n---\n"):]
+
+	lines := strings.Split(front, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || key == "" || strings.HasPrefix(key, " ") || strings.HasPrefix(key, "-") {
+			return m, body, fmt.Errorf("%w: line %d: want \"key: value\", got %q", ErrInvalidFrontMatter, i+2, line)
+		}
+		value = strings.TrimSpace(value)
+		// the indented lines below the key, e.g. the items of a list
+		var block []string
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "-")) {
+			i++
+			block = append(block, lines[i])
+		}
+
+		var err error
+		switch key {
+		case "subject":
+			m.Subject, err = parseScalar(value)
+		case "version":
+			m.Version, err = strconv.Atoi(value)
+		case "description":
+			m.Description, err = parseScalar(value)
+		case "owner":
+			m.Owner, err = parseScalar(value)
+		case "producers":
+			m.Producers, err = parseList(value, block)
+		case "consumers":
+			m.Consumers, err = parseList(value, block)
+		case "tags":
+			m.Tags, err = parseList(value, block)
+		case "status":
+			m.Status, err = parseScalar(value)
+			switch m.Status {
+			case "", StatusDeprecated, StatusRetired, StatusRenamed:
+			default:
+				err = fmt.Errorf("want %s, %s or %s, got %q", StatusDeprecated, StatusRetired, StatusRenamed, m.Status)
+			}
+		case "sunset":
+			m.Sunset, err = parseScalar(value)
+		case "replaced_by":
+			m.ReplacedBy, err = parseScalar(value)
+		case "retired":
+			m.Retired, err = parseScalar(value)
+		case "renamed_to":
+			m.RenamedTo, err = parseScalar(value)
+		default:
+			m.extra = append(append(m.extra, line), block...)
+		}
+		if err != nil {
+			return m, body, fmt.Errorf("%w: %s: %v", ErrInvalidFrontMatter, key, err)
+		}
+	}
+	return m, body, nil
+}
+
+// parseScalar parses a plain, single quoted or double quoted YAML value.
+func parseScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s, nil
+}
+
+// parseList parses a list given either inline as "[a, b]" or as the
+// "- item" lines of block.
+func parseList(value string, block []string) ([]string, error) {
+	var items []string
+	if value != "" {
+		if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("want a list, got %q", value)
+		}
+		for _, item := range strings.Split(value[1:len(value)-1], ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			s, err := parseScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, s)
+		}
+		return items, nil
+	}
+	for _, line := range block {
+		item, ok := strings.CutPrefix(strings.TrimSpace(line), "- ")
+		if !ok {
+			return nil, fmt.Errorf("want a \"- item\" line, got %q", line)
+		}
+		s, err := parseScalar(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, nil
+}
+
+// plainScalar matches values which need no quotes in YAML.
+var plainScalar = regexp.MustCompile(`^[A-Za-z_./][A-Za-z0-9 _./@()-]*$`)
+
+// quote returns s as a YAML value, quoted unless it is plain text which YAML
+// would not read as something else.
+func quote(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if plainScalar.MatchString(s) && !strings.HasSuffix(s, " ") {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// FrontMatter returns the metadata as YAML front matter, including its
+// "---" lines. Empty values are written too, to show what can be set, apart
+// from the status of the version and its dates, which the tools set.
+func (m Metadata) FrontMatter() string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "subject: %s\n", quote(m.Subject))
+	fmt.Fprintf(&b, "version: %d\n", m.Version)
+	fmt.Fprintf(&b, "description: %s\n", quote(m.Description))
+	fmt.Fprintf(&b, "owner: %s\n", quote(m.Owner))
+	writeList(&b, "producers", m.Producers)
+	writeList(&b, "consumers", m.Consumers)
+	writeList(&b, "tags", m.Tags)
+	for _, field := range []struct{ key, value string }{
+		{"status", m.Status},
+		{"sunset", m.Sunset},
+		{"replaced_by", m.ReplacedBy},
+		{"retired", m.Retired},
+		{"renamed_to", m.RenamedTo},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", field.key, quote(field.value))
+		}
+	}
+	for _, line := range m.extra {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+func writeList(b *strings.Builder, key string, items []string) {
+	if len(items) == 0 {
+		fmt.Fprintf(b, "%s: []\n", key)
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, item := range items {
+		fmt.Fprintf(b, "  - %s\n", quote(item))
+	}
+}
@@ -0,0 +1,82 @@
+package catalog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFrontMatter(t *testing.T) {
+	m := Metadata{
+		Subject:     "order.{order_id}.created",
+		Version:     2,
+		Description: "Emitted when an order is placed: once per order.",
+		Owner:       "team-orders",
+		Producers:   []string{"order-service"},
+		Consumers:   []string{"billing", "true"},
+	}
+	want := `---
+subject: "order.{order_id}.created"
+version: 2
+description: "Emitted when an order is placed: once per order."
+owner: team-orders
+producers:
+  - order-service
+consumers:
+  - billing
+  - "true"
+tags: []
+---
+`
+	if got := m.FrontMatter(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	parsed, body, err := ParseDoc(want + "# Event\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "# Event\n" {
+		t.Errorf("got body %q", body)
+	}
+	if !reflect.DeepEqual(parsed, m) {
+		t.Errorf("got %+v, want %+v", parsed, m)
+	}
+}
+
+func TestParseDoc(t *testing.T) {
+	doc := `---
+subject: order.created
+version: 1
+# a comment
+owner: 'team ''orders'''
+tags: [orders, "checkout"]
+producers:
+- order-service # the only one
+sla:
+  latency: 5s
+---
+body`
+	m, body, err := ParseDoc(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "body" || m.Subject != "order.created" || m.Version != 1 || m.Owner != "team 'orders'" ||
+		!reflect.DeepEqual(m.Tags, []string{"orders", "checkout"}) || !reflect.DeepEqual(m.Producers, []string{"order-service"}) {
+		t.Errorf("got %+v, body %q", m, body)
+	}
+	want := "---\nsubject: order.created\nversion: 1\ndescription: \"\"\nowner: \"team 'orders'\"\n" +
+		"producers:\n  - order-service\nconsumers: []\ntags:\n  - orders\n  - checkout\nsla:\n  latency: 5s\n---\n"
+	if got := m.FrontMatter(); got != want {
+		t.Errorf("unknown keys not kept, got\n%s", got)
+	}
+
+	if m, body, err := ParseDoc("# Event\n"); err != nil || body != "# Event\n" || !reflect.DeepEqual(m, Metadata{}) {
+		t.Errorf("docs without front matter: got %+v, %q, %v", m, body, err)
+	}
+	for _, doc := range []string{"---\nsubject: a.b\n", "---\n  subject: a.b\n---\n", "---\ntags: orders\n---\n", "---\nversion: one\n---\n", "---\nstatus: sunset\n---\n"} {
+		if _, _, err := ParseDoc(doc); !errors.Is(err, ErrInvalidFrontMatter) {
+			t.Errorf("ParseDoc(%q) = %v, want ErrInvalidFrontMatter", doc, err)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The headings of the sections of docs which list the services producing
+// and consuming an event, when its front matter does not.
+const (
+	producedByHeading = "Produced By"
+	consumedByHeading = "Consumed By"
+)
+
+// mdHeading matches any markdown heading, and mdLinkText a link, of which
+// docServices keeps the text.
+var (
+	mdHeading  = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*$`)
+	mdLinkText = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// docServices returns the services listed in the section of the docs body
+// under heading: the items of its list, or else the comma separated names
+// on its lines. What follows a name after ": " or " - " describes it, and
+// TODO placeholders are skipped.
+func docServices(body, heading string) []string {
+	var items, lines []string
+	in, code := false, false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			code = !code
+			continue
+		}
+		if code {
+			continue
+		}
+		if m := mdHeading.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			in = strings.EqualFold(m[1], heading)
+			continue
+		}
+		if !in || strings.TrimSpace(line) == "" || strings.Contains(line, "TODO") {
+			continue
+		}
+		if mdList.MatchString(line) {
+			items = append(items, mdList.ReplaceAllString(line, ""))
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	if len(items) > 0 {
+		lines = items
+	}
+
+	var services []string
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		line = mdLinkText.ReplaceAllString(line, "$1")
+		line = strings.NewReplacer("**", "", "`", "").Replace(line)
+		for _, sep := range []string{": ", " - ", " — "} {
+			line, _, _ = strings.Cut(line, sep)
+		}
+		for _, name := range strings.Split(line, ",") {
+			name = strings.Trim(strings.TrimSpace(name), "_*.")
+			if name != "" && !seen[name] {
+				seen[name] = true
+				services = append(services, name)
+			}
+		}
+	}
+	return services
+}
+
+// Service is a service producing or consuming events.
+type Service struct {
+	Name string
+	// Produces and Consumes are the versions of the events the service
+	// produces and consumes, sorted as by Walk.
+	Produces []Event
+	Consumes []Event
+	// Upstream are the services producing the events the service
+	// consumes, and Downstream the services consuming the events it
+	// produces, sorted.
+	Upstream   []string
+	Downstream []string
+}
+
+// inUse reports whether a version may still be produced or consumed, that is
+// whether it was not renamed.
+func inUse(e Event) bool {
+	return e.Metadata.Status != StatusRenamed
+}
+
+// Services returns the services producing or consuming the versions of
+// events still in use, sorted by name.
+func Services(events []Event) []Service {
+	byName := make(map[string]*Service)
+	service := func(name string) *Service {
+		if byName[name] == nil {
+			byName[name] = &Service{Name: name}
+		}
+		return byName[name]
+	}
+	for _, event := range events {
+		if !inUse(event) {
+			continue
+		}
+		for _, name := range event.Metadata.Producers {
+			s := service(name)
+			s.Produces = append(s.Produces, event)
+			s.Downstream = append(s.Downstream, event.Metadata.Consumers...)
+		}
+		for _, name := range event.Metadata.Consumers {
+			s := service(name)
+			s.Consumes = append(s.Consumes, event)
+			s.Upstream = append(s.Upstream, event.Metadata.Producers...)
+		}
+	}
+	services := make([]Service, 0, len(byName))
+	for _, s := range byName {
+		s.Upstream = uniqueSorted(s.Upstream)
+		s.Downstream = uniqueSorted(s.Downstream)
+		services = append(services, *s)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// uniqueSorted returns the distinct strings of s, sorted.
+func uniqueSorted(s []string) []string {
+	sort.Strings(s)
+	var unique []string
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// graph is the dependency graph of services and events, over the versions
+// still in use.
+type graph struct {
+	services []string
+	subjects []string
+	edges    []edge
+}
+
+// edge is a service producing or consuming a subject.
+type edge struct {
+	service, subject string
+	produces         bool
+}
+
+func newGraph(events []Event) graph {
+	var g graph
+	services := make(map[string]bool)
+	subjects := make(map[string]bool)
+	edges := make(map[edge]bool)
+	add := func(e edge) {
+		if !services[e.service] {
+			services[e.service] = true
+			g.services = append(g.services, e.service)
+		}
+		if !edges[e] {
+			edges[e] = true
+			g.edges = append(g.edges, e)
+		}
+	}
+	for _, event := range events {
+		if !inUse(event) {
+			continue
+		}
+		subject := event.Subject.String()
+		if !subjects[subject] {
+			subjects[subject] = true
+			g.subjects = append(g.subjects, subject)
+		}
+		for _, name := range event.Metadata.Producers {
+			add(edge{name, subject, true})
+		}
+		for _, name := range event.Metadata.Consumers {
+			add(edge{name, subject, false})
+		}
+	}
+	sort.Strings(g.services)
+	return g
+}
+
+// Mermaid returns the dependency graph of the services producing and
+// consuming events as a Mermaid flowchart, with services as boxes and
+// subjects as stadiums. Edges go from producers to subjects and from
+// subjects to consumers.
+func Mermaid(events []Event) string {
+	g := newGraph(events)
+	serviceIDs := make(map[string]string)
+	subjectIDs := make(map[string]string)
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, name := range g.services {
+		serviceIDs[name] = fmt.Sprintf("s%d", i+1)
+		fmt.Fprintf(&b, "  %s[%s]\n", serviceIDs[name], mermaidLabel(name))
+	}
+	for i, subject := range g.subjects {
+		subjectIDs[subject] = fmt.Sprintf("e%d", i+1)
+		fmt.Fprintf(&b, "  %s([%s])\n", subjectIDs[subject], mermaidLabel(subject))
+	}
+	for _, e := range g.edges {
+		if e.produces {
+			fmt.Fprintf(&b, "  %s --> %s\n", serviceIDs[e.service], subjectIDs[e.subject])
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", subjectIDs[e.subject], serviceIDs[e.service])
+		}
+	}
+	return b.String()
+}
+
+// mermaidLabel quotes a node label for Mermaid.
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// DOT returns the dependency graph of the services producing and consuming
+// events in the Graphviz DOT language, with services as boxes and subjects
+// as ellipses. Edges go from producers to subjects and from subjects to
+// consumers.
+func DOT(events []Event) string {
+	g := newGraph(events)
+	var b strings.Builder
+	b.WriteString("digraph catalog {\n  rankdir=LR;\n")
+	for _, name := range g.services {
+		fmt.Fprintf(&b, "  %s [shape=box, label=%s];\n", dotID("service:"+name), dotID(name))
+	}
+	for _, subject := range g.subjects {
+		fmt.Fprintf(&b, "  %s [shape=ellipse, label=%s];\n", dotID("event:"+subject), dotID(subject))
+	}
+	for _, e := range g.edges {
+		service, subject := dotID("service:"+e.service), dotID("event:"+e.subject)
+		if e.produces {
+			fmt.Fprintf(&b, "  %s -> %s;\n", service, subject)
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", subject, service)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotID quotes an ID for DOT. Services and subjects are given distinct IDs
+// by their prefix, as they may share a name.
+func dotID(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Warnings returns a warning for each active version with no producers or
+// no consumers.
+func Warnings(events []Event) []string {
+	var warnings []string
+	for _, event := range events {
+		if !event.Active() {
+			continue
+		}
+		var missing []string
+		if len(event.Metadata.Producers) == 0 {
+			missing = append(missing, "producers")
+		}
+		if len(event.Metadata.Consumers) == 0 {
+			missing = append(missing, "consumers")
+		}
+		if len(missing) > 0 {
+			warnings = append(warnings, fmt.Sprintf("v%d of %s has no %s", event.Version, event.Subject, strings.Join(missing, " or ")))
+		}
+	}
+	return warnings
+}
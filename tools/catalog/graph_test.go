@@ -0,0 +1,96 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocServices(t *testing.T) {
+	body := "## Produced By\n[orders-service](https://example.com) - emits it on checkout\n\n" +
+		"## Consumed By\n- `billing-service`: charges the order\n- _shipping-service_\n- _TODO: List services_\n\n" +
+		"## Payload Schema\n- not-a-service\n"
+	if got, want := docServices(body, producedByHeading), []string{"orders-service"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("producers: got %q, want %q", got, want)
+	}
+	if got, want := docServices(body, consumedByHeading), []string{"billing-service", "shipping-service"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("consumers: got %q, want %q", got, want)
+	}
+	if got := docServices("## Produced By\n_TODO: Add the service that emits this event._\n", producedByHeading); got != nil {
+		t.Errorf("got %q, want none", got)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"order/{order_id}/created/v1.md": "---\nproducers: [orders]\nconsumers: [billing]\nstatus: deprecated\n---\n",
+		"order/{order_id}/created/v2.md": "## Produced By\norders\n\n## Consumed By\n- billing\n- shipping\n",
+		"order/placed/v1.md":             "---\nproducers: [orders]\nstatus: renamed\nrenamed_to: order.{order_id}.created\n---\n",
+		"invoice/paid/v1.md":             "---\nproducers: [billing]\n---\n",
+		"user/joined/v1.md":              "# Event\n",
+	})
+	events, err := Walk(dir, DefaultMaxDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	services := Services(events)
+	var names []string
+	for _, s := range services {
+		names = append(names, s.Name)
+	}
+	if want := []string{"billing", "orders", "shipping"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got services %q, want %q", names, want)
+	}
+	billing := services[0]
+	if len(billing.Produces) != 1 || len(billing.Consumes) != 2 {
+		t.Errorf("billing produces %d and consumes %d versions, want 1 and 2", len(billing.Produces), len(billing.Consumes))
+	}
+	if want := []string{"orders"}; !reflect.DeepEqual(billing.Upstream, want) {
+		t.Errorf("got upstream %q, want %q", billing.Upstream, want)
+	}
+	if want := []string{"billing", "shipping"}; !reflect.DeepEqual(services[1].Downstream, want) {
+		t.Errorf("got downstream %q, want %q", services[1].Downstream, want)
+	}
+
+	wantMermaid := `flowchart LR
+  s1["billing"]
+  s2["orders"]
+  s3["shipping"]
+  e1(["invoice.paid"])
+  e2(["order.{order_id}.created"])
+  e3(["user.joined"])
+  s1 --> e1
+  s2 --> e2
+  e2 --> s1
+  e2 --> s3
+`
+	if got := Mermaid(events); got != wantMermaid {
+		t.Errorf("got\n%s\nwant\n%s", got, wantMermaid)
+	}
+	wantDOT := `digraph catalog {
+  rankdir=LR;
+  "service:billing" [shape=box, label="billing"];
+  "service:orders" [shape=box, label="orders"];
+  "service:shipping" [shape=box, label="shipping"];
+  "event:invoice.paid" [shape=ellipse, label="invoice.paid"];
+  "event:order.{order_id}.created" [shape=ellipse, label="order.{order_id}.created"];
+  "event:user.joined" [shape=ellipse, label="user.joined"];
+  "service:billing" -> "event:invoice.paid";
+  "service:orders" -> "event:order.{order_id}.created";
+  "event:order.{order_id}.created" -> "service:billing";
+  "event:order.{order_id}.created" -> "service:shipping";
+}
+`
+	if got := DOT(events); got != wantDOT {
+		t.Errorf("got\n%s\nwant\n%s", got, wantDOT)
+	}
+
+	wantWarnings := []string{
+		"v1 of invoice.paid has no consumers",
+		"v1 of user.joined has no producers or consumers",
+	}
+	if got := Warnings(events); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("got warnings %q, want %q", got, wantWarnings)
+	}
+}
@@ -0,0 +1,201 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Event is a version of an event, documented in the events directory.
+type Event struct {
+	Subject Subject
+	Version int
+	// Path is the path of the docs, e.g.
+	// "events/order/{order_id}/created/v1.md", with forward slashes.
+	Path     string
+	Metadata Metadata
+	// Schemas are the schema files of the version, in the order of
+	// FormatJSONSchema, FormatProtobuf and FormatAvro.
+	Schemas []Schema
+}
+
+// Description returns the description of the version from its front
+// matter, or else from its schemas.
+func (e Event) Description() string {
+	if e.Metadata.Description != "" {
+		return e.Metadata.Description
+	}
+	for _, schema := range e.Schemas {
+		if schema.Description != "" {
+			return schema.Description
+		}
+	}
+	for _, schema := range e.Schemas {
+		if schema.Title != "" {
+			return schema.Title
+		}
+	}
+	return ""
+}
+
+// Active reports whether the version is neither deprecated, retired nor
+// renamed.
+func (e Event) Active() bool {
+	return e.Metadata.Status == ""
+}
+
+// Walk returns the versions of the events documented in baseDir, sorted by
+// subject and version. Producers and consumers missing from the front
+// matter of docs are taken from their "Produced By" and "Consumed By"
+// sections. Docs with an invalid subject, version or front matter are all
+// reported in the error.
+func Walk(baseDir string, maxDepth int) ([]Event, error) {
+	var events []Event
+	// invalid lists the docs whose subject, version or front matter is invalid
+	var invalid []string
+
+	err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".md") || !strings.HasPrefix(filepath.Base(path), "v") {
+			return nil
+		}
+
+		relPath := filepath.ToSlash(path)
+		subjectPath := strings.TrimPrefix(filepath.Dir(relPath), filepath.ToSlash(baseDir)+"/")
+		subject, err := ParseSubjectPath(subjectPath, maxDepth)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", relPath, err))
+			return nil
+		}
+		version, err := ParseVersion(strings.TrimSuffix(filepath.Base(relPath), ".md"))
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", relPath, err))
+			return nil
+		}
+		doc, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		meta, body, err := ParseDoc(string(doc))
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", relPath, err))
+			return nil
+		}
+		// docs without producers or consumers in their front matter may
+		// list them in their sections
+		if len(meta.Producers) == 0 {
+			meta.Producers = docServices(body, producedByHeading)
+		}
+		if len(meta.Consumers) == 0 {
+			meta.Consumers = docServices(body, consumedByHeading)
+		}
+
+		event := Event{Subject: subject, Version: version, Path: relPath, Metadata: meta}
+		for _, s := range schemaExts {
+			schemaPath := filepath.Join(filepath.Dir(path), fmt.Sprintf("v%d.%s", version, s.ext))
+			if _, err := os.Stat(schemaPath); err != nil {
+				continue
+			}
+			schema, err := readSchema(schemaPath, s.format)
+			if err != nil {
+				return err
+			}
+			event.Schemas = append(event.Schemas, schema)
+		}
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking %s: %w", baseDir, err)
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid event docs:\n  %s", strings.Join(invalid, "\n  "))
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if a, b := events[i].Subject.String(), events[j].Subject.String(); a != b {
+			return a < b
+		}
+		return events[i].Version < events[j].Version
+	})
+	return events, nil
+}
+
+// Index returns the markdown index of events, with a table of the versions
+// of the subjects of each domain. Versions which are no longer active are
+// struck through.
+func Index(events []Event) string {
+	// domain -> rows of the versions of its events, in order
+	grouped := make(map[string][]string)
+	for _, event := range events {
+		domain := event.Subject.Domain()
+		link := fmt.Sprintf("[v%d](./%s)", event.Version, event.Path)
+		if !event.Active() {
+			link = "~~" + link + "~~"
+		}
+		var formats []string
+		for _, schema := range event.Schemas {
+			formats = append(formats, fmt.Sprintf("[%s](./%s)", schema.Format, schema.Path))
+		}
+		grouped[domain] = append(grouped[domain], "| "+strings.Join([]string{
+			"`" + event.Subject.String() + "`",
+			link,
+			cell(event.Description()),
+			cell(event.Metadata.Owner),
+			strings.Join(formats, ", "),
+			cell(strings.Join(event.Metadata.Producers, ", ")),
+			cell(strings.Join(event.Metadata.Consumers, ", ")),
+			cell(status(event.Metadata)),
+		}, " | ")+" |\n")
+	}
+
+	var b strings.Builder
+	b.WriteString("# Event Index\n\n")
+
+	domains := make([]string, 0, len(grouped))
+	for domain := range grouped {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		b.WriteString(fmt.Sprintf("## %s\n\n", domain))
+		b.WriteString("| Subject | Version | Description | Owner | Formats | Producers | Consumers | Status |\n")
+		b.WriteString("|---------|---------|-------------|-------|---------|-----------|-----------|--------|\n")
+		for _, row := range grouped[domain] {
+			b.WriteString(row)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// status describes the status of a version for the index.
+func status(m Metadata) string {
+	switch m.Status {
+	case "":
+		return "active"
+	case StatusDeprecated:
+		s := "deprecated"
+		if m.Sunset != "" {
+			s += ", sunset " + m.Sunset
+		}
+		if m.ReplacedBy != "" {
+			s += ", replaced by " + m.ReplacedBy
+		}
+		return s
+	case StatusRenamed:
+		return "renamed to " + m.RenamedTo
+	}
+	return m.Status
+}
+
+// cell escapes text for a markdown table cell.
+func cell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
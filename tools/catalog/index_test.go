@@ -0,0 +1,87 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, by their path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"order/{order_id}/created/v1.md": "---\nsubject: order.{order_id}.created\nversion: 1\nowner: team-orders\n" +
+			"producers: [orders]\nconsumers: [billing, shipping]\nstatus: deprecated\nsunset: \"2025-06-30\"\n---\n# Event\n",
+		"order/{order_id}/created/v1.schema.json": `{"title": "OrderCreatedEvent", "description": "An order was placed | created"}`,
+		"order/{order_id}/created/v1.proto":       "syntax = \"proto3\";\n\n// OrderCreatedEvent is an order.\nmessage OrderCreatedEvent {}\n",
+		"order/{order_id}/created/v2.md":          "---\ndescription: An order was created.\n---\n# Event\n",
+		"order/{order_id}/created/v10.md":         "# Event\n",
+		"order/{order_id}/created/v10.avsc":       `{"type": "record", "name": "OrderCreatedEvent", "doc": "From Avro."}`,
+		"user/{user_id}/joined/v1.md":             "# Event\n",
+		"user/{user_id}/joined/v1.proto":          "syntax = \"proto3\";\n\n// UserJoinedEvent is sent when a user\n// joins.\nmessage UserJoinedEvent {}\n",
+		"user/{user_id}/joined/notes.txt":         "not docs",
+	})
+
+	events, err := Walk(dir, DefaultMaxDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.ToSlash(dir)
+	got := strings.ReplaceAll(Index(events), base+"/", "")
+	want := `# Event Index
+
+## order
+
+| Subject | Version | Description | Owner | Formats | Producers | Consumers | Status |
+|---------|---------|-------------|-------|---------|-----------|-----------|--------|
+| ` + "`order.{order_id}.created`" + ` | ~~[v1](./order/{order_id}/created/v1.md)~~ | An order was placed \| created | team-orders | [JSON Schema](./order/{order_id}/created/v1.schema.json), [Protobuf](./order/{order_id}/created/v1.proto) | orders | billing, shipping | deprecated, sunset 2025-06-30 |
+| ` + "`order.{order_id}.created`" + ` | [v2](./order/{order_id}/created/v2.md) | An order was created. |  |  |  |  | active |
+| ` + "`order.{order_id}.created`" + ` | [v10](./order/{order_id}/created/v10.md) | From Avro. |  | [Avro](./order/{order_id}/created/v10.avsc) |  |  | active |
+
+## user
+
+| Subject | Version | Description | Owner | Formats | Producers | Consumers | Status |
+|---------|---------|-------------|-------|---------|-----------|-----------|--------|
+| ` + "`user.{user_id}.joined`" + ` | [v1](./user/{user_id}/joined/v1.md) | UserJoinedEvent is sent when a user joins. |  | [Protobuf](./user/{user_id}/joined/v1.proto) |  |  | active |
+
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWalkInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Order/created/v1.md":  "# Event\n",
+		"order/created/v01.md": "# Event\n",
+		"order/placed/v1.md":   "---\ntags: orders\n---\n",
+		"order/voided/v1.md":   "# Event\n",
+	})
+	_, err := Walk(dir, DefaultMaxDepth)
+	if err == nil {
+		t.Fatal("got no error")
+	}
+	for _, want := range []string{"Order/created/v1.md: " + ErrInvalidSubject.Error(), "v01.md: " + ErrInvalidVersion.Error(), "placed/v1.md: " + ErrInvalidFrontMatter.Error()} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "voided") {
+		t.Errorf("unexpected error %q", err)
+	}
+}
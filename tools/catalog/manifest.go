@@ -0,0 +1,223 @@
+package catalog
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ManifestJSONSchema is the JSON schema of the manifest.
+//
+//go:embed manifest.schema.json
+var ManifestJSONSchema []byte
+
+// ManifestSchemaID is the $id of ManifestJSONSchema, which manifests refer
+// to.
+const ManifestSchemaID = "https://example.com/schemas/event-catalog/v1.schema.json"
+
+// Manifest lists the events of the catalog for programs to discover them.
+type Manifest struct {
+	Schema string          `json:"$schema"`
+	Events []ManifestEvent `json:"events"`
+}
+
+// ManifestEvent is a subject and its versions.
+type ManifestEvent struct {
+	Subject   string            `json:"subject"`
+	Domain    string            `json:"domain"`
+	EventType string            `json:"event_type"`
+	Params    []string          `json:"params"`
+	Versions  []ManifestVersion `json:"versions"`
+}
+
+// ManifestVersion is a version of an event.
+type ManifestVersion struct {
+	Version     int    `json:"version"`
+	Docs        string `json:"docs"`
+	Description string `json:"description,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	// Status is "active", StatusDeprecated, StatusRetired or StatusRenamed.
+	Status     string           `json:"status"`
+	Sunset     string           `json:"sunset,omitempty"`
+	ReplacedBy string           `json:"replaced_by,omitempty"`
+	RenamedTo  string           `json:"renamed_to,omitempty"`
+	Producers  []string         `json:"producers"`
+	Consumers  []string         `json:"consumers"`
+	Tags       []string         `json:"tags"`
+	Schemas    []ManifestSchema `json:"schemas"`
+}
+
+// ManifestSchema is a schema file of a version.
+type ManifestSchema struct {
+	// Format is "json", "proto" or "avro", as for event-template -format.
+	Format string `json:"format"`
+	Path   string `json:"path"`
+	// SHA256 is the hex encoded SHA-256 hash of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// manifestFormats maps schema formats to their names in the manifest.
+var manifestFormats = map[string]string{
+	FormatJSONSchema: "json",
+	FormatProtobuf:   "proto",
+	FormatAvro:       "avro",
+}
+
+// nonNil returns s, or an empty slice if s is nil, so that it is encoded
+// as [] rather than null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// NewManifest returns the manifest of events, which are sorted as by Walk.
+func NewManifest(events []Event) Manifest {
+	m := Manifest{Schema: ManifestSchemaID, Events: []ManifestEvent{}}
+	for _, event := range events {
+		subject := event.Subject.String()
+		if n := len(m.Events); n == 0 || m.Events[n-1].Subject != subject {
+			m.Events = append(m.Events, ManifestEvent{
+				Subject:   subject,
+				Domain:    event.Subject.Domain(),
+				EventType: strings.Join(event.Subject.Types(), "."),
+				Params:    nonNil(event.Subject.Params()),
+			})
+		}
+		meta := event.Metadata
+		v := ManifestVersion{
+			Version:     event.Version,
+			Docs:        event.Path,
+			Description: event.Description(),
+			Owner:       meta.Owner,
+			Status:      meta.Status,
+			Sunset:      meta.Sunset,
+			ReplacedBy:  meta.ReplacedBy,
+			RenamedTo:   meta.RenamedTo,
+			Producers:   nonNil(meta.Producers),
+			Consumers:   nonNil(meta.Consumers),
+			Tags:        nonNil(meta.Tags),
+			Schemas:     []ManifestSchema{},
+		}
+		if v.Status == "" {
+			v.Status = "active"
+		}
+		for _, schema := range event.Schemas {
+			v.Schemas = append(v.Schemas, ManifestSchema{
+				Format: manifestFormats[schema.Format],
+				Path:   schema.Path,
+				SHA256: schema.SHA256,
+			})
+		}
+		last := &m.Events[len(m.Events)-1]
+		last.Versions = append(last.Versions, v)
+	}
+	return m
+}
+
+// JSON returns the manifest as indented JSON.
+func (m Manifest) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// YAML returns the manifest as YAML, with the same structure as its JSON.
+func (m Manifest) YAML() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out bytes.Buffer
+	if err := writeYAML(&out, dec, 0); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeYAML writes the JSON value read from dec as YAML, indented by indent
+// spaces. Keys are written in the order they were encoded.
+func writeYAML(w *bytes.Buffer, dec *json.Decoder, indent int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	pad := strings.Repeat(" ", indent)
+	switch tok {
+	case json.Delim('{'):
+		if !dec.More() {
+			w.WriteString("{}\n")
+			_, err := dec.Token()
+			return err
+		}
+		for first := true; dec.More(); first = false {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			// the first key of a map in a list follows the "- "
+			if !first || w.Len() == 0 || w.Bytes()[w.Len()-1] == '\n' {
+				w.WriteString(pad)
+			}
+			w.WriteString(quote(fmt.Sprint(key)) + ":")
+			if err := writeYAMLValue(w, dec, indent); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		if !dec.More() {
+			w.WriteString("[]\n")
+			_, err := dec.Token()
+			return err
+		}
+		for dec.More() {
+			w.WriteString(pad + "- ")
+			if err := writeYAML(w, dec, indent+2); err != nil {
+				return err
+			}
+		}
+	default:
+		w.WriteString(yamlScalar(tok) + "\n")
+		return nil
+	}
+	// the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// writeYAMLValue writes the value of a map key, on the same line if it is a
+// scalar or empty, and else indented on the following lines.
+func writeYAMLValue(w *bytes.Buffer, dec *json.Decoder, indent int) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	value := json.NewDecoder(bytes.NewReader(raw))
+	value.UseNumber()
+	switch s := string(raw); {
+	case s == "[]" || s == "{}" || !strings.HasPrefix(s, "[") && !strings.HasPrefix(s, "{"):
+		w.WriteString(" ")
+		return writeYAML(w, value, indent)
+	default:
+		w.WriteString("\n")
+		return writeYAML(w, value, indent+2)
+	}
+}
+
+// yamlScalar returns a JSON scalar as a YAML value.
+func yamlScalar(tok json.Token) string {
+	switch v := tok.(type) {
+	case string:
+		return quote(v)
+	case nil:
+		return "null"
+	}
+	return fmt.Sprint(tok)
+}
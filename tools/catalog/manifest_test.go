@@ -0,0 +1,269 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"order/{order_id}/created/v1.md":    "---\nowner: team-orders\nproducers: [orders]\nstatus: deprecated\nsunset: \"2025-06-30\"\n---\n",
+		"order/{order_id}/created/v1.proto": "message OrderCreatedEvent {}\n",
+		"order/{order_id}/created/v2.md":    "---\ndescription: \"An order: created.\"\n---\n",
+		"user/joined/v1.md":                 "# Event\n",
+	})
+	events, err := Walk(dir, DefaultMaxDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManifest(events)
+
+	b, err := m.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.ToSlash(dir)
+	got := strings.ReplaceAll(string(b), base+"/", "")
+	want := `{
+  "$schema": "https://example.com/schemas/event-catalog/v1.schema.json",
+  "events": [
+    {
+      "subject": "order.{order_id}.created",
+      "domain": "order",
+      "event_type": "order.created",
+      "params": [
+        "order_id"
+      ],
+      "versions": [
+        {
+          "version": 1,
+          "docs": "order/{order_id}/created/v1.md",
+          "description": "OrderCreatedEvent",
+          "owner": "team-orders",
+          "status": "deprecated",
+          "sunset": "2025-06-30",
+          "producers": [
+            "orders"
+          ],
+          "consumers": [],
+          "tags": [],
+          "schemas": [
+            {
+              "format": "proto",
+              "path": "order/{order_id}/created/v1.proto",
+              "sha256": "f6ffa174e2f8625b5f31c19d6cf4a289e024bf18b0f994d604c9489467a77388"
+            }
+          ]
+        },
+        {
+          "version": 2,
+          "docs": "order/{order_id}/created/v2.md",
+          "description": "An order: created.",
+          "status": "active",
+          "producers": [],
+          "consumers": [],
+          "tags": [],
+          "schemas": []
+        }
+      ]
+    },
+    {
+      "subject": "user.joined",
+      "domain": "user",
+      "event_type": "user.joined",
+      "params": [],
+      "versions": [
+        {
+          "version": 1,
+          "docs": "user/joined/v1.md",
+          "status": "active",
+          "producers": [],
+          "consumers": [],
+          "tags": [],
+          "schemas": []
+        }
+      ]
+    }
+  ]
+}
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	y, err := m.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = strings.ReplaceAll(string(y), base+"/", "")
+	want = `"$schema": "https://example.com/schemas/event-catalog/v1.schema.json"
+events:
+  - subject: "order.{order_id}.created"
+    domain: order
+    event_type: order.created
+    params:
+      - order_id
+    versions:
+      - version: 1
+        docs: "order/{order_id}/created/v1.md"
+        description: OrderCreatedEvent
+        owner: team-orders
+        status: deprecated
+        sunset: "2025-06-30"
+        producers:
+          - orders
+        consumers: []
+        tags: []
+        schemas:
+          - format: proto
+            path: "order/{order_id}/created/v1.proto"
+            sha256: f6ffa174e2f8625b5f31c19d6cf4a289e024bf18b0f994d604c9489467a77388
+      - version: 2
+        docs: "order/{order_id}/created/v2.md"
+        description: "An order: created."
+        status: active
+        producers: []
+        consumers: []
+        tags: []
+        schemas: []
+  - subject: user.joined
+    domain: user
+    event_type: user.joined
+    params: []
+    versions:
+      - version: 1
+        docs: user/joined/v1.md
+        status: active
+        producers: []
+        consumers: []
+        tags: []
+        schemas: []
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestManifestJSONSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(ManifestJSONSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["$id"] != ManifestSchemaID {
+		t.Errorf("got $id %q, want %q", schema["$id"], ManifestSchemaID)
+	}
+
+	// a manifest of versions of every status and schema format
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"order/{order_id}/created/v1.md":          "---\nowner: team-orders\nproducers: [orders]\nconsumers: [billing]\ntags: [orders]\nstatus: deprecated\nsunset: \"2025-06-30\"\nreplaced_by: order.{order_id}.created v2\n---\n",
+		"order/{order_id}/created/v1.schema.json": `{"description": "An order was created"}`,
+		"order/{order_id}/created/v1.proto":       "message OrderCreatedEvent {}\n",
+		"order/{order_id}/created/v1.avsc":        `{"type": "record", "name": "OrderCreatedEvent", "fields": []}`,
+		"order/{order_id}/created/v2.md":          "---\nstatus: retired\nretired: \"2025-07-01\"\n---\n",
+		"order/{order_id}/created/v3.md":          "# Event\n",
+		"order/placed/v1.md":                      "---\nstatus: renamed\nrenamed_to: order.{order_id}.created\n---\n",
+	})
+	events, err := Walk(dir, DefaultMaxDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewManifest(events).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest interface{}
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range validate(schema, schema, manifest, "") {
+		t.Errorf("the manifest does not match its schema: %s\n%s", e, b)
+	}
+
+	// and the validation catches what the schema rules out
+	manifest.(map[string]interface{})["events"].([]interface{})[0].(map[string]interface{})["versions"].([]interface{})[0].(map[string]interface{})["status"] = "sunset"
+	if errs := validate(schema, schema, manifest, ""); len(errs) != 1 || !strings.Contains(errs[0], "/events/0/versions/0/status") {
+		t.Errorf("got errors %q for an invalid status", errs)
+	}
+}
+
+// validate returns the errors of value against schema, a part of the JSON
+// schema root, for the keywords the manifest schema uses.
+func validate(root, schema map[string]interface{}, value interface{}, pointer string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		defs := root["$defs"].(map[string]interface{})
+		return validate(root, defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{}), value, pointer)
+	}
+	fail := func(format string, args ...interface{}) []string {
+		return []string{pointer + ": " + fmt.Sprintf(format, args...)}
+	}
+	if c, ok := schema["const"]; ok && value != c {
+		return fail("got %v, want %v", value, c)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, v := range enum {
+			found = found || v == value
+		}
+		if !found {
+			return fail("got %v, want one of %v", value, enum)
+		}
+	}
+
+	var errs []string
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("got %T, want an object", value)
+		}
+		for _, name := range schema["required"].([]interface{}) {
+			if _, ok := object[name.(string)]; !ok {
+				errs = append(errs, fail("missing %s", name)...)
+			}
+		}
+		properties := schema["properties"].(map[string]interface{})
+		for name, v := range object {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				errs = append(errs, fail("unknown property %s", name)...)
+				continue
+			}
+			errs = append(errs, validate(root, property, v, pointer+"/"+name)...)
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fail("got %T, want an array", value)
+		}
+		if min, ok := schema["minItems"].(float64); ok && float64(len(array)) < min {
+			errs = append(errs, fail("got %d items, want at least %v", len(array), min)...)
+		}
+		for i, item := range array {
+			errs = append(errs, validate(root, schema["items"].(map[string]interface{}), item, fmt.Sprintf("%s/%d", pointer, i))...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fail("got %T, want a string", value)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(s) {
+			errs = append(errs, fail("%q does not match %s", s, pattern)...)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fail("got %v, want an integer", value)
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			errs = append(errs, fail("got %v, want at least %v", n, min)...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,144 @@
+package catalog
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// The inline markdown renderMarkdown understands, matched after the text is
+// HTML escaped.
+var (
+	mdLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdBold   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdItalic = regexp.MustCompile(`(^|[^\w*])[_*]([^_*]+)[_*]($|[^\w*])`)
+	mdStrike = regexp.MustCompile(`~~([^~]+)~~`)
+	mdList   = regexp.MustCompile(`^\s*([-*]|\d+\.) `)
+	mdHeader = regexp.MustCompile(`^(#{1,6}) (.*)$`)
+)
+
+// renderMarkdown renders the markdown of event docs as HTML. It understands
+// the subset of markdown the docs use: headings, paragraphs, lists, block
+// quotes, tables and fenced code, with code, bold, italic, struck through
+// text and links inline. Links to other docs are changed to their pages.
+func renderMarkdown(md string) string {
+	var b strings.Builder
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "```"):
+			lang := strings.TrimPrefix(trimmed, "```")
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			if lang != "" {
+				b.WriteString(`<pre><code class="language-` + html.EscapeString(lang) + `">`)
+			} else {
+				b.WriteString("<pre><code>")
+			}
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+		case mdHeader.MatchString(trimmed):
+			m := mdHeader.FindStringSubmatch(trimmed)
+			level := string(rune('0' + len(m[1])))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+		case strings.HasPrefix(trimmed, ">"):
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")))
+			}
+			i--
+			b.WriteString("<blockquote>" + renderMarkdown(strings.Join(quote, "\n")) + "</blockquote>\n")
+		case mdList.MatchString(line):
+			tag := "ul"
+			if m := mdList.FindStringSubmatch(line); m[1] != "-" && m[1] != "*" {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for ; i < len(lines) && mdList.MatchString(lines[i]); i++ {
+				b.WriteString("<li>" + renderInline(mdList.ReplaceAllString(lines[i], "")) + "</li>\n")
+			}
+			i--
+			b.WriteString("</" + tag + ">\n")
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "|-"):
+			b.WriteString("<table>\n<thead><tr>")
+			for _, c := range tableCells(trimmed) {
+				b.WriteString("<th>" + renderInline(c) + "</th>")
+			}
+			b.WriteString("</tr></thead>\n<tbody>\n")
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				b.WriteString("<tr>")
+				for _, c := range tableCells(strings.TrimSpace(lines[i])) {
+					b.WriteString("<td>" + renderInline(c) + "</td>")
+				}
+				b.WriteString("</tr>\n")
+			}
+			i--
+			b.WriteString("</tbody>\n</table>\n")
+		default:
+			var para []string
+			for ; i < len(lines) && isParagraph(lines[i]); i++ {
+				para = append(para, renderInline(strings.TrimSpace(lines[i])))
+			}
+			i--
+			b.WriteString("<p>" + strings.Join(para, "<br>\n") + "</p>\n")
+		}
+	}
+	return b.String()
+}
+
+// isParagraph reports whether line continues a paragraph, rather than being
+// blank or starting another block.
+func isParagraph(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, ">") &&
+		!strings.HasPrefix(trimmed, "|") && !mdHeader.MatchString(trimmed) && !mdList.MatchString(line)
+}
+
+// tableCells splits a markdown table row into its cells.
+func tableCells(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	// escaped pipes are part of the cell
+	cells := strings.Split(strings.ReplaceAll(row, `\|`, "\x00"), "|")
+	for i, c := range cells {
+		cells[i] = strings.ReplaceAll(strings.TrimSpace(c), "\x00", "|")
+	}
+	return cells
+}
+
+// renderInline renders the inline markdown of a line as HTML.
+func renderInline(s string) string {
+	var b strings.Builder
+	// odd parts are code spans, which are not formatted
+	for i, part := range strings.Split(s, "`") {
+		if i%2 == 1 {
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		part = html.EscapeString(part)
+		part = mdLink.ReplaceAllStringFunc(part, func(m string) string {
+			sub := mdLink.FindStringSubmatch(m)
+			return `<a href="` + docLink(html.UnescapeString(sub[2])) + `">` + sub[1] + "</a>"
+		})
+		part = mdBold.ReplaceAllString(part, "<strong>$1</strong>")
+		part = mdStrike.ReplaceAllString(part, "<del>$1</del>")
+		part = mdItalic.ReplaceAllString(part, "$1<em>$2</em>$3")
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// docLink returns the escaped href of a link in docs, pointing to the page
+// of linked docs rather than their markdown. Links to scripts are dropped.
+func docLink(href string) string {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "javascript:") {
+		return "#"
+	}
+	if !strings.Contains(href, "://") && strings.HasSuffix(href, ".md") {
+		href = strings.TrimSuffix(href, ".md") + ".html"
+	}
+	return html.EscapeString(href)
+}
@@ -0,0 +1,21 @@
+package catalog
+
+import "testing"
+
+func TestRenderMarkdown(t *testing.T) {
+	md := "# Event: `order.{order_id}.created`\n**Version**: 1\n\n" +
+		"> ⚠️ **Deprecated**: use [v2](./v2.md).\n\n" +
+		"- _one_\n- ~~two~~ <b>\n\n" +
+		"| Field | Type |\n|-------|------|\n| `a\\|b` | string |\n\n" +
+		"```json\n{\"a\": \"<b>\"}\n```\n[x](JavaScript:void)\n"
+	want := "<h1>Event: <code>order.{order_id}.created</code></h1>\n" +
+		"<p><strong>Version</strong>: 1</p>\n" +
+		"<blockquote><p>⚠️ <strong>Deprecated</strong>: use <a href=\"./v2.html\">v2</a>.</p>\n</blockquote>\n" +
+		"<ul>\n<li><em>one</em></li>\n<li><del>two</del> &lt;b&gt;</li>\n</ul>\n" +
+		"<table>\n<thead><tr><th>Field</th><th>Type</th></tr></thead>\n<tbody>\n<tr><td><code>a|b</code></td><td>string</td></tr>\n</tbody>\n</table>\n" +
+		"<pre><code class=\"language-json\">{&#34;a&#34;: &#34;&lt;b&gt;&#34;}</code></pre>\n" +
+		"<p><a href=\"#\">x</a></p>\n"
+	if got := renderMarkdown(md); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
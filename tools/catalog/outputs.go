@@ -0,0 +1,88 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Outputs are the files written from the events of the catalog. Empty paths
+// are skipped.
+type Outputs struct {
+	// Index is the path of the markdown index.
+	Index string
+	// Manifest is the path of the JSON manifest. Its JSON schema is written
+	// next to it, as ".schema.json".
+	Manifest string
+	// YAML also writes the manifest as YAML next to it, as ".yaml".
+	YAML bool
+	// Graph is the path, without extension, of the dependency graph of
+	// services, written as Mermaid (".mmd") and DOT (".dot").
+	Graph string
+	// Site is the directory of the static HTML site.
+	Site string
+}
+
+// DefaultOutputs are the outputs of generate-index without flags, which
+// event-template regenerates after changing events too.
+var DefaultOutputs = Outputs{Index: "index.md", Manifest: "catalog.json", Graph: "graph"}
+
+// YAMLPath returns the path of the YAML manifest, e.g. "catalog.yaml".
+func (o Outputs) YAMLPath() string {
+	return o.manifestBase() + ".yaml"
+}
+
+// manifestBase returns the path of the manifest without its extension.
+func (o Outputs) manifestBase() string {
+	return strings.TrimSuffix(o.Manifest, filepath.Ext(o.Manifest))
+}
+
+// Write writes the outputs of events, and returns the paths written, in
+// order. The site is listed as its index.html.
+func (o Outputs) Write(events []Event) ([]string, error) {
+	type output struct {
+		path   string
+		encode func() ([]byte, error)
+	}
+	var outputs []output
+	if o.Index != "" {
+		outputs = append(outputs, output{o.Index, func() ([]byte, error) { return []byte(Index(events)), nil }})
+	}
+	if o.Manifest != "" {
+		manifest := NewManifest(events)
+		outputs = append(outputs,
+			output{o.Manifest, manifest.JSON},
+			// the schema of the manifest is published next to it
+			output{o.manifestBase() + ".schema.json", func() ([]byte, error) { return ManifestJSONSchema, nil }},
+		)
+		if o.YAML {
+			outputs = append(outputs, output{o.YAMLPath(), manifest.YAML})
+		}
+	}
+	if o.Graph != "" {
+		outputs = append(outputs,
+			output{o.Graph + ".mmd", func() ([]byte, error) { return []byte(Mermaid(events)), nil }},
+			output{o.Graph + ".dot", func() ([]byte, error) { return []byte(DOT(events)), nil }},
+		)
+	}
+
+	var written []string
+	for _, out := range outputs {
+		b, err := out.encode()
+		if err == nil {
+			err = os.WriteFile(out.path, b, 0644)
+		}
+		if err != nil {
+			return written, fmt.Errorf("writing %s: %w", out.path, err)
+		}
+		written = append(written, out.path)
+	}
+	if o.Site != "" {
+		if err := WriteSite(o.Site, events); err != nil {
+			return written, fmt.Errorf("writing the site: %w", err)
+		}
+		written = append(written, filepath.Join(o.Site, "index.html"))
+	}
+	return written, nil
+}
@@ -0,0 +1,48 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOutputsWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"events/order/created/v1.md": "---\nproducers: [orders]\n---\n# Event\n",
+	})
+	events, err := Walk(filepath.Join(dir, "events"), DefaultMaxDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := Outputs{
+		Index:    filepath.Join(dir, "index.md"),
+		Manifest: filepath.Join(dir, "catalog.json"),
+		YAML:     true,
+		Graph:    filepath.Join(dir, "graph"),
+		Site:     filepath.Join(dir, "site"),
+	}
+	written, err := outputs.Write(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, name := range []string{"index.md", "catalog.json", "catalog.schema.json", "catalog.yaml", "graph.mmd", "graph.dot", "site/index.html"} {
+		want = append(want, filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	if !reflect.DeepEqual(written, want) {
+		t.Errorf("got %q, want %q", written, want)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// empty paths are skipped
+	written, err = Outputs{Index: filepath.Join(dir, "index.md")}.Write(events)
+	if err != nil || len(written) != 1 {
+		t.Errorf("got %q, %v, want only index.md", written, err)
+	}
+}
@@ -0,0 +1,86 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The formats of event schemas.
+const (
+	FormatJSONSchema = "JSON Schema"
+	FormatProtobuf   = "Protobuf"
+	FormatAvro       = "Avro"
+)
+
+// schemaExts maps the extensions of the schema files of a version, as in
+// "v1.schema.json", to their formats, in the order they are listed.
+var schemaExts = []struct{ ext, format string }{
+	{"schema.json", FormatJSONSchema},
+	{"proto", FormatProtobuf},
+	{"avsc", FormatAvro},
+}
+
+// Schema is a schema file of an event version.
+type Schema struct {
+	Format string
+	// Path is the path of the schema, e.g.
+	// "events/order/{order_id}/created/v1.schema.json", with forward
+	// slashes.
+	Path string
+	// Title is the "title" of a JSON schema, the name of the first message
+	// of a protobuf schema or the "name" of an Avro schema.
+	Title string
+	// Description is the "description" of a JSON schema, the comment of the
+	// first message of a protobuf schema or the "doc" of an Avro schema.
+	Description string
+	// SHA256 is the hex encoded SHA-256 hash of the file.
+	SHA256 string
+
+	content []byte
+}
+
+// protoMessage matches the first message of a protobuf schema and the
+// comment lines before it.
+var protoMessage = regexp.MustCompile(`((?:^|\n)(?://[^\n]*\n)*)message\s+(\w+)`)
+
+// readSchema reads the title and description of a schema file. Schemas which
+// cannot be parsed have neither.
+func readSchema(path, format string) (Schema, error) {
+	schema := Schema{Format: format, Path: filepath.ToSlash(path)}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return schema, err
+	}
+	schema.content = b
+	sum := sha256.Sum256(b)
+	schema.SHA256 = hex.EncodeToString(sum[:])
+	switch format {
+	case FormatJSONSchema:
+		var s struct{ Title, Description string }
+		if json.Unmarshal(b, &s) == nil {
+			schema.Title, schema.Description = s.Title, s.Description
+		}
+	case FormatAvro:
+		var s struct{ Name, Doc string }
+		if json.Unmarshal(b, &s) == nil {
+			schema.Title, schema.Description = s.Name, s.Doc
+		}
+	case FormatProtobuf:
+		if m := protoMessage.FindStringSubmatch(string(b)); m != nil {
+			schema.Title = m[2]
+			var lines []string
+			for _, line := range strings.Split(strings.TrimSpace(m[1]), "\n") {
+				if line = strings.TrimSpace(strings.TrimPrefix(line, "//")); line != "" {
+					lines = append(lines, line)
+				}
+			}
+			schema.Description = strings.Join(lines, " ")
+		}
+	}
+	return schema, nil
+}
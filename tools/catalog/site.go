@@ -0,0 +1,248 @@
+package catalog
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// siteFiles holds the templates and assets of the static site.
+//
+//go:embed site
+var siteFiles embed.FS
+
+var siteTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"pagePath":    pagePath,
+	"servicePath": servicePath,
+	"status":      status,
+}).ParseFS(siteFiles, "site/*.html.tmpl"))
+
+// siteAssets are copied to the root of the site as they are.
+var siteAssets = []string{"style.css", "search.js"}
+
+// sitePage is the data of the page of a version.
+type sitePage struct {
+	Event
+	// Root is the relative path from the page to the root of the site.
+	Root        string
+	Status      string
+	Description string
+	Docs        template.HTML
+	Formats     []siteLink
+	Fields      []Field
+	Example     string
+	Versions    []siteLink
+	// Previous is the previous version, which Changes and SchemaDiffs are
+	// against, or 0 if there is none.
+	Previous    int
+	Changes     []fieldChange
+	SchemaDiffs []schemaDiff
+}
+
+// siteLink is a link to another page or file of the site.
+type siteLink struct {
+	Text    string
+	URL     string
+	Current bool
+	Active  bool
+}
+
+// schemaDiff is the diff of a schema file from the previous version.
+type schemaDiff struct {
+	Format string
+	Lines  []diffLine
+}
+
+// siteEntry is an event listed on the index page of the site.
+type siteEntry struct {
+	Event
+	URL         string
+	Status      string
+	Description string
+	// Search is the lower case text the search matches: the subject, its
+	// description and the paths of its fields.
+	Search string
+}
+
+// mdJSON matches the first fenced JSON block of docs.
+var mdJSON = regexp.MustCompile("(?s)```json\n(.*?)\n```")
+
+// pagePath returns the path of the page of a version in the site, e.g.
+// "events/order/{order_id}/created/v1.html".
+func pagePath(event Event) string {
+	return path.Join(append([]string{"events"}, event.Subject.Tokens...)...) + fmt.Sprintf("/v%d.html", event.Version)
+}
+
+// serviceName matches the characters of service names which are replaced in
+// the paths of their pages.
+var serviceName = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// servicePath returns the path of the page of a service in the site, e.g.
+// "services/billing-service.html".
+func servicePath(name string) string {
+	return "services/" + serviceName.ReplaceAllString(strings.ToLower(name), "-") + ".html"
+}
+
+// WriteSite writes a self-contained static site of events to dir: an index
+// page to search the events by subject and field, a page for each version
+// with its docs, fields, example payload, other versions and changes from
+// the previous version, and a page for each service with the events it
+// produces and consumes and the services it depends on. Schema files are
+// copied next to the pages, so that the links of the docs work.
+func WriteSite(dir string, events []Event) error {
+	var entries []siteEntry
+	for i, event := range events {
+		doc, err := os.ReadFile(event.Path)
+		if err != nil {
+			return err
+		}
+		_, body, err := ParseDoc(string(doc))
+		if err != nil {
+			return fmt.Errorf("%s: %w", event.Path, err)
+		}
+		page := sitePage{
+			Event:       event,
+			Root:        strings.Repeat("../", len(event.Subject.Tokens)+1),
+			Status:      status(event.Metadata),
+			Description: event.Description(),
+			Docs:        template.HTML(renderMarkdown(body)),
+			Example:     example(body, event.Schemas),
+		}
+		for _, schema := range event.Schemas {
+			page.Formats = append(page.Formats, siteLink{Text: schema.Format, URL: path.Base(schema.Path)})
+			page.Fields = append(page.Fields, schema.Fields()...)
+		}
+		// the versions of the subject, and the one before this one
+		var previous *Event
+		for j := range events {
+			other := events[j]
+			if other.Subject.String() != event.Subject.String() {
+				continue
+			}
+			page.Versions = append(page.Versions, siteLink{
+				Text:    fmt.Sprintf("v%d", other.Version),
+				URL:     fmt.Sprintf("v%d.html", other.Version),
+				Current: j == i,
+				Active:  other.Active(),
+			})
+			if j < i {
+				previous = &events[j]
+			}
+		}
+		if previous != nil {
+			page.Previous = previous.Version
+			page.Changes, page.SchemaDiffs = changes(*previous, event)
+		}
+
+		pageFile := filepath.Join(dir, filepath.FromSlash(pagePath(event)))
+		if err := writeSiteFile(pageFile, "page.html.tmpl", page); err != nil {
+			return err
+		}
+		for _, schema := range event.Schemas {
+			if err := os.WriteFile(filepath.Join(filepath.Dir(pageFile), path.Base(schema.Path)), schema.content, 0644); err != nil {
+				return err
+			}
+		}
+
+		search := []string{event.Subject.String()}
+		if page.Description != "" {
+			search = append(search, page.Description)
+		}
+		for _, field := range page.Fields {
+			search = append(search, field.Path)
+		}
+		entries = append(entries, siteEntry{
+			Event:       event,
+			URL:         pagePath(event),
+			Status:      page.Status,
+			Description: page.Description,
+			Search:      strings.ToLower(strings.Join(search, "\n")),
+		})
+	}
+
+	services := Services(events)
+	for _, service := range services {
+		if err := writeSiteFile(filepath.Join(dir, filepath.FromSlash(servicePath(service.Name))), "service.html.tmpl", service); err != nil {
+			return err
+		}
+	}
+	index := struct {
+		Events   []siteEntry
+		Services []Service
+	}{entries, services}
+	if err := writeSiteFile(filepath.Join(dir, "index.html"), "index.html.tmpl", index); err != nil {
+		return err
+	}
+	for _, asset := range siteAssets {
+		b, err := siteFiles.ReadFile("site/" + asset)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, asset), b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSiteFile executes the site template name with data into path.
+func writeSiteFile(path, name string, data interface{}) error {
+	var b bytes.Buffer
+	if err := siteTemplates.ExecuteTemplate(&b, name, data); err != nil {
+		return fmt.Errorf("rendering %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
+
+// example returns the example payload of a version: the first JSON block of
+// its docs, or else the first of the "examples" of its JSON schema.
+func example(body string, schemas []Schema) string {
+	if m := mdJSON.FindStringSubmatch(body); m != nil {
+		return m[1]
+	}
+	for _, schema := range schemas {
+		if schema.Format != FormatJSONSchema {
+			continue
+		}
+		var root struct {
+			Examples []json.RawMessage `json:"examples"`
+		}
+		if json.Unmarshal(schema.content, &root) != nil || len(root.Examples) == 0 {
+			continue
+		}
+		var b bytes.Buffer
+		if json.Indent(&b, root.Examples[0], "", "  ") == nil {
+			return b.String()
+		}
+	}
+	return ""
+}
+
+// changes returns the fields changed from the previous version to event, and
+// the diffs of the schema files of the formats both versions have.
+func changes(previous, event Event) ([]fieldChange, []schemaDiff) {
+	var old, new []Field
+	var diffs []schemaDiff
+	for _, schema := range event.Schemas {
+		new = append(new, schema.Fields()...)
+		for _, before := range previous.Schemas {
+			if before.Format == schema.Format {
+				diffs = append(diffs, schemaDiff{Format: schema.Format, Lines: diffLines(string(before.content), string(schema.content))})
+			}
+		}
+	}
+	for _, schema := range previous.Schemas {
+		old = append(old, schema.Fields()...)
+	}
+	return diffFields(old, new), diffs
+}
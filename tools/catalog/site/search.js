@@ -0,0 +1,28 @@
+// Filters the events of the index by the words of the search box, each of
+// which must be part of the subject, description or a field of an event.
+document.addEventListener("DOMContentLoaded", function () {
+  var input = document.getElementById("search");
+  var rows = document.querySelectorAll("#events tbody tr");
+  var empty = document.getElementById("no-results");
+
+  function search() {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    var shown = 0;
+    rows.forEach(function (row) {
+      var text = row.getAttribute("data-search");
+      var match = words.every(function (word) {
+        return text.indexOf(word) !== -1;
+      });
+      row.hidden = !match;
+      if (match) {
+        shown++;
+      }
+    });
+    empty.hidden = shown > 0;
+  }
+
+  input.addEventListener("input", search);
+  // a search can be linked to with ?q=
+  input.value = new URLSearchParams(location.search).get("q") || "";
+  search();
+});
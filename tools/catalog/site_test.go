@@ -0,0 +1,73 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSite(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"events/order/{order_id}/created/v1.md":          "---\nowner: team-orders\nstatus: deprecated\n---\n# Order Created\nSee [v2](./v2.md).\n",
+		"events/order/{order_id}/created/v1.schema.json": `{"properties": {"order_id": {"type": "string"}, "note": {"type": "string"}}}`,
+		"events/order/{order_id}/created/v2.md":          "# Order Created\n## Produced By\norders\n```json\n{\"order_id\": \"ord_1\"}\n```\n",
+		"events/order/{order_id}/created/v2.schema.json": `{"required": ["order_id"], "properties": {"order_id": {"type": "string"}, "total": {"type": "number"}}}`,
+	})
+	events, err := Walk(filepath.Join(dir, "events"), DefaultMaxDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := filepath.Join(dir, "site")
+	if err := WriteSite(site, events); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(site, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	read("style.css")
+	read("search.js")
+	read("events/order/{order_id}/created/v1.schema.json")
+
+	for name, wants := range map[string][]string{
+		"index.html": {
+			`<tr data-search="order.{order_id}.created` + "\norder_id\nnote" + `" class="inactive">`,
+			`<a href="events/order/%7border_id%7d/created/v2.html">v2</a>`,
+			`<li><a href="services/orders.html">orders</a></li>`,
+		},
+		"events/order/{order_id}/created/v1.html": {
+			`<link rel="stylesheet" href="../../../../style.css">`,
+			"<strong>v1</strong>\n<a href=\"v2.html\">v2</a>",
+			`<dd class="inactive">deprecated</dd>`,
+			`<a href="./v2.html">v2</a>`,
+		},
+		"events/order/{order_id}/created/v2.html": {
+			"<a href=\"v1.html\" class=\"inactive\">v1</a>\n<strong>v2</strong>",
+			`<tr><td><code>order_id</code></td><td>string</td><td>yes</td><td></td></tr>`,
+			`<code class="language-json">{&#34;order_id&#34;: &#34;ord_1&#34;}</code>`,
+			"<h2>Changes from v1</h2>",
+			`<tr class="changed"><td>changed</td><td><code>order_id</code></td><td>string</td><td>string, required</td></tr>`,
+			`<tr class="added"><td>added</td><td><code>total</code></td><td></td><td>number</td></tr>`,
+			`<tr class="removed"><td>removed</td><td><code>note</code></td><td>string</td><td></td></tr>`,
+			`<span class="removed">- {&#34;properties&#34;`,
+			`<dt>Producers</dt><dd><a href="../../../../services/orders.html">orders</a></dd>`,
+		},
+		"services/orders.html": {
+			`<a href="../events/order/%7border_id%7d/created/v2.html">v2</a>`,
+		},
+	} {
+		got := read(name)
+		for _, want := range wants {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s does not contain %q:\n%s", name, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Package catalog holds what the event registry tools share about the
+// events directory and the docs in it.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxDepth is the default maximum number of tokens of a subject.
+const DefaultMaxDepth = 6
+
+var (
+	// ErrInvalidSubject is returned for subjects which do not follow the
+	// subject syntax.
+	ErrInvalidSubject = errors.New("invalid subject")
+	// ErrInvalidVersion is returned for versions which are not like v1.
+	ErrInvalidVersion = errors.New("invalid version")
+)
+
+// Subject is an event subject like a NATS subject, e.g.
+// "order.{order_id}.created": lowercase tokens separated by dots, some of
+// which are {snake_case} parameters. The docs and schemas of a subject are
+// in the directory events/order/{order_id}/created.
+type Subject struct {
+	Tokens []string
+}
+
+// ParseSubject parses a subject of at most maxDepth tokens. Surrounding
+// whitespace is ignored.
+func ParseSubject(s string, maxDepth int) (Subject, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Subject{}, fmt.Errorf("%w: subject is empty; subjects are like order.{order_id}.created", ErrInvalidSubject)
+	}
+	return parseTokens(s, strings.Split(s, "."), maxDepth)
+}
+
+// ParseSubjectPath parses the subject of a directory relative to the events
+// directory, e.g. "order/{order_id}/created".
+func ParseSubjectPath(path string, maxDepth int) (Subject, error) {
+	tokens := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	return parseTokens(strings.Join(tokens, "."), tokens, maxDepth)
+}
+
+func parseTokens(s string, tokens []string, maxDepth int) (Subject, error) {
+	invalid := func(format string, args ...interface{}) (Subject, error) {
+		return Subject{}, fmt.Errorf("%w %q: %s", ErrInvalidSubject, s, fmt.Sprintf(format, args...))
+	}
+	if len(tokens) < 2 {
+		return invalid("subjects need at least two tokens, e.g. order.created")
+	}
+	if maxDepth > 0 && len(tokens) > maxDepth {
+		return invalid("it has %d tokens, more than the maximum of %d", len(tokens), maxDepth)
+	}
+	params := make(map[string]bool)
+	for i, token := range tokens {
+		switch {
+		case token == "":
+			return invalid("token %d is empty; tokens are separated by single dots", i+1)
+		case token == "*" || token == ">":
+			return invalid("%q is a NATS wildcard; use a {param} for a token which varies", token)
+		case strings.HasPrefix(token, "{") || strings.HasSuffix(token, "}"):
+			name := strings.TrimSuffix(strings.TrimPrefix(token, "{"), "}")
+			if !strings.HasPrefix(token, "{") || !strings.HasSuffix(token, "}") || !isSnakeCase(name) {
+				return invalid("parameter %q must be a {snake_case} name, e.g. {%s}", token, suggest(name))
+			}
+			if i == 0 {
+				return invalid("the first token must not be a parameter, as it groups events by domain")
+			}
+			if params[name] {
+				return invalid("parameter %q appears more than once", token)
+			}
+			params[name] = true
+		case strings.ToLower(token) != token:
+			return invalid("token %q must be lowercase, e.g. %q", token, suggest(token))
+		case !isToken(token):
+			return invalid(`token %q must start with a letter and contain only letters, digits, "_" and "-"`, token)
+		}
+	}
+	return Subject{Tokens: tokens}, nil
+}
+
+// isToken reports whether s is a lowercase token like "order_item".
+func isToken(s string) bool {
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '_' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isSnakeCase reports whether s is a snake_case name like "order_id".
+func isSnakeCase(s string) bool {
+	return isToken(s) && !strings.Contains(s, "-") && !strings.HasSuffix(s, "_") && !strings.Contains(s, "__")
+}
+
+// suggest turns a malformed token into a snake_case one,
+// for error messages.
+func suggest(s string) string {
+	var words []string
+	word := ""
+	upper := false
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			// a capital starts a word, unless it follows another, as in "ID"
+			if word != "" && !upper {
+				words = append(words, word)
+				word = ""
+			}
+			word += string(r - 'A' + 'a')
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			word += string(r)
+		default:
+			if word != "" {
+				words = append(words, word)
+			}
+			word = ""
+		}
+		upper = r >= 'A' && r <= 'Z'
+	}
+	if word != "" {
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return "id"
+	}
+	return strings.Join(words, "_")
+}
+
+// IsParam reports whether a token is a {param}.
+func IsParam(token string) bool {
+	return strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}")
+}
+
+// String returns the subject, e.g. "order.{order_id}.created".
+func (s Subject) String() string {
+	return strings.Join(s.Tokens, ".")
+}
+
+// Dir returns the directory of the subject's files, e.g.
+// "events/order/{order_id}/created".
+func (s Subject) Dir(baseDir string) string {
+	return filepath.Join(append([]string{baseDir}, s.Tokens...)...)
+}
+
+// Domain returns the first token of the subject, which groups its events.
+func (s Subject) Domain() string {
+	return s.Tokens[0]
+}
+
+// Params returns the names of the {param} tokens of the subject.
+func (s Subject) Params() []string {
+	var params []string
+	for _, token := range s.Tokens {
+		if IsParam(token) {
+			params = append(params, strings.Trim(token, "{}"))
+		}
+	}
+	return params
+}
+
+// Types returns the tokens of the subject which are not parameters.
+func (s Subject) Types() []string {
+	var types []string
+	for _, token := range s.Tokens {
+		if !IsParam(token) {
+			types = append(types, token)
+		}
+	}
+	return types
+}
+
+// ParseVersion parses a version like "v1" into its number.
+func ParseVersion(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "v"))
+	if !strings.HasPrefix(s, "v") || err != nil || n < 1 || strconv.Itoa(n) != s[1:] {
+		return 0, fmt.Errorf("%w %q: versions are v followed by a whole number from 1, e.g. v1", ErrInvalidVersion, s)
+	}
+	return n, nil
+}
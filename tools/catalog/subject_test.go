@@ -0,0 +1,83 @@
+package catalog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+		// err is a part of the error message, if the subject is invalid
+		err string
+	}{
+		{subject: "order.created", want: "order.created"},
+		{subject: " order.{order_id}.line_item.added-v2 ", want: "order.{order_id}.line_item.added-v2"},
+		{subject: "", err: "subject is empty"},
+		{subject: "order", err: "at least two tokens"},
+		{subject: "a.b.c.d.e.f.g", err: "7 tokens, more than the maximum of 6"},
+		{subject: "order..created", err: "token 2 is empty"},
+		{subject: "order.created.", err: "token 3 is empty"},
+		{subject: "order.*.created", err: `"*" is a NATS wildcard`},
+		{subject: "order.>", err: `">" is a NATS wildcard`},
+		{subject: "Order.Created", err: `token "Order" must be lowercase, e.g. "order"`},
+		{subject: "order.{id", err: `parameter "{id" must be a {snake_case} name, e.g. {id}`},
+		{subject: "order.{OrderID}.created", err: "e.g. {order_id}"},
+		{subject: "order.{order-id}.created", err: "e.g. {order_id}"},
+		{subject: "{order_id}.created", err: "first token must not be a parameter"},
+		{subject: "order.{id}.item.{id}", err: `parameter "{id}" appears more than once`},
+		{subject: "order.1st", err: "must start with a letter"},
+		{subject: "order.créé", err: "must start with a letter"},
+	}
+	for _, test := range tests {
+		t.Run(test.subject, func(t *testing.T) {
+			s, err := ParseSubject(test.subject, DefaultMaxDepth)
+			if test.err != "" {
+				if !errors.Is(err, ErrInvalidSubject) || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.String() != test.want {
+				t.Errorf("got %q, want %q", s, test.want)
+			}
+		})
+	}
+}
+
+func TestSubject(t *testing.T) {
+	s, err := ParseSubjectPath("order/{order_id}/item/{item_id}/added", DefaultMaxDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.String(); got != "order.{order_id}.item.{item_id}.added" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := s.Domain(); got != "order" {
+		t.Errorf("Domain() = %q", got)
+	}
+	if got := strings.Join(s.Params(), ","); got != "order_id,item_id" {
+		t.Errorf("Params() = %q", got)
+	}
+	if got := strings.Join(s.Types(), ","); got != "order,item,added" {
+		t.Errorf("Types() = %q", got)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for s, want := range map[string]int{"v1": 1, "v12": 12} {
+		if n, err := ParseVersion(s); err != nil || n != want {
+			t.Errorf("ParseVersion(%q) = %d, %v, want %d", s, n, err, want)
+		}
+	}
+	for _, s := range []string{"", "1", "v", "v0", "v01", "v1.2", "v+1", "V1", "version1"} {
+		if _, err := ParseVersion(s); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("ParseVersion(%q) = %v, want ErrInvalidVersion", s, err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"catalog"
+)
+
+// versionFile matches the docs and schema files of an event version, e.g.
+// "v2.md" or "v2.schema.json".
+var versionFile = regexp.MustCompile(`^v(\d+)\.(md|schema\.json|proto|avsc)$`)
+
+// bump copies the docs and schema of the latest version of an event to the
+// next version.
+func bump(args []string) {
+	flags := flag.NewFlagSet("bump", flag.ExitOnError)
+	maxDepth := flags.Int("max-depth", catalog.DefaultMaxDepth, "maximum number of tokens of a subject")
+	parseArgs(flags, args, 1)
+	subject, err := catalog.ParseSubject(flags.Arg(0), *maxDepth)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	dirPath := subject.Dir("events")
+
+	// files maps each version to the extensions of its files
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		fatalf("Failed to read %s: %v", dirPath, err)
+	}
+	files := make(map[int][]string)
+	latest := 0
+	for _, entry := range entries {
+		m := versionFile.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+		v, _ := strconv.Atoi(m[1])
+		files[v] = append(files[v], m[2])
+		if v > latest {
+			latest = v
+		}
+	}
+	if latest == 0 {
+		fatalf("No versions of %s found in %s", subject, dirPath)
+	}
+	sort.Strings(files[latest])
+	next := latest + 1
+
+	mdPath := filepath.Join(dirPath, fmt.Sprintf("v%d.md", latest))
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		fatalf("Failed to read %s: %v", mdPath, err)
+	}
+	meta, body, err := catalog.ParseDoc(string(md))
+	if err != nil {
+		fatalf("%s: %v", mdPath, err)
+	}
+	switch meta.Status {
+	case catalog.StatusRenamed:
+		fatalf("%s was renamed to %s; bump the new subject instead", subject, meta.RenamedTo)
+	case catalog.StatusRetired:
+		fatalf("v%d of %s is retired", latest, subject)
+	}
+	if todos := todoLines(string(md)); len(todos) > 0 {
+		fatalf("%s still contains TODOs on lines %s; finish documenting v%d before bumping it",
+			mdPath, strings.Join(todos, ", "), latest)
+	}
+
+	bumped := make(map[string]string)
+	for _, ext := range files[latest] {
+		from := filepath.Join(dirPath, fmt.Sprintf("v%d.%s", latest, ext))
+		to := filepath.Join(dirPath, fmt.Sprintf("v%d.%s", next, ext))
+		if _, err := os.Stat(to); err == nil {
+			fatalf("File already exists: %s", to)
+		}
+		b, err := os.ReadFile(from)
+		if err != nil {
+			fatalf("Failed to read %s: %v", from, err)
+		}
+		switch ext {
+		case "md":
+			bumped[to] = bumpMarkdown(activeDoc(string(b), meta, body), latest, next)
+		case "schema.json":
+			bumped[to] = bumpJSONSchema(string(b), latest, next)
+		case "proto":
+			bumped[to] = bumpProto(string(b), latest, next)
+		case "avsc":
+			bumped[to] = bumpAvro(string(b), latest, next)
+		}
+	}
+
+	paths := make([]string, 0, len(bumped))
+	for path := range bumped {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte(bumped[path]), 0644); err != nil {
+			fatalf("Failed to write %s: %v", path, err)
+		}
+		fmt.Printf("✅ Created %s\n", path)
+	}
+	if len(paths) == 1 {
+		fmt.Printf("⚠️  v%d of %s has no schema file to copy\n", latest, subject)
+	}
+}
+
+// todoLines returns the numbers of the lines containing "TODO".
+func todoLines(s string) []string {
+	var lines []string
+	for i, line := range strings.Split(s, "\n") {
+		if strings.Contains(line, "TODO") {
+			lines = append(lines, strconv.Itoa(i+1))
+		}
+	}
+	return lines
+}
+
+// activeDoc returns the docs of a deprecated version without its status and
+// notice, as the version copied from it starts active.
+func activeDoc(md string, meta catalog.Metadata, body string) string {
+	if meta.Status == "" && meta.Sunset == "" && meta.ReplacedBy == "" && meta.Retired == "" && meta.RenamedTo == "" {
+		return md
+	}
+	meta.Status, meta.Sunset, meta.ReplacedBy, meta.Retired, meta.RenamedTo = "", "", "", "", ""
+	return meta.FrontMatter() + removeNotice(body)
+}
+
+// replaceVersion replaces every match of pattern, in which %d stands for the
+// old version, with repl, in which %d stands for the new one.
+func replaceVersion(s, pattern, repl string, from, to int) string {
+	re := regexp.MustCompile(fmt.Sprintf(pattern, from))
+	return re.ReplaceAllString(s, fmt.Sprintf(repl, to))
+}
+
+// bumpMarkdown updates the version, links to the files of the version and
+// the example payload of an event's docs and their front matter, and adds a
+// changelog entry for the new version.
+func bumpMarkdown(md string, from, to int) string {
+	md = replaceVersion(md, `(?m)^(version: *)%d$`, "${1}%d", from, to)
+	md = replaceVersion(md, `(\*\*Version\*\*: *v?)%d\b`, "${1}%d", from, to)
+	md = replaceVersion(md, `\bv%d(\.(schema\.json|proto|avsc|md))`, "v%d$1", from, to)
+	md = replaceVersion(md, `("version": *)%d\b`, "${1}%d", from, to)
+	md = replaceVersion(md, `("schema_version": *")%d\.\d+"`, `${1}%d.0"`, from, to)
+
+	entry := fmt.Sprintf("### v%d\n- _TODO: Describe what changed since v%d._\n\n", to, from)
+	if i := strings.Index(md, "## Changelog\n"); i >= 0 {
+		i += len("## Changelog\n")
+		for strings.HasPrefix(md[i:], "\n") {
+			i++
+		}
+		return md[:i] + entry + md[i:]
+	}
+	section := "## Changelog\n" + entry
+	if i := strings.Index(md, "## Payload Schema\n"); i >= 0 {
+		return md[:i] + section + md[i:]
+	}
+	return strings.TrimRight(md, "\n") + "\n\n" + section
+}
+
+// schemaProperty matches a property of a JSON schema without nested objects,
+// e.g. "version": {"type": "integer", "const": 1}.
+func schemaProperty(name string) *regexp.Regexp {
+	return regexp.MustCompile(`"` + name + `"\s*:\s*\{[^{}]*\}`)
+}
+
+// bumpJSONSchema updates the $id of a JSON schema, and the constant version
+// and default schema_version of its metadata.
+func bumpJSONSchema(schema string, from, to int) string {
+	id := regexp.MustCompile(`("\$id"\s*:\s*")([^"]*)"`)
+	schema = id.ReplaceAllStringFunc(schema, func(m string) string {
+		parts := id.FindStringSubmatch(m)
+		u := parts[2]
+		versioned := regexp.MustCompile(fmt.Sprintf(`\bv%d\b`, from))
+		if versioned.MatchString(u) {
+			u = versioned.ReplaceAllString(u, fmt.Sprintf("v%d", to))
+		} else {
+			// the previous $id was unversioned, e.g. ".../order-created-event.schema.json"
+			u = strings.TrimSuffix(strings.TrimSuffix(u, ".json"), ".schema")
+			u += fmt.Sprintf("/v%d.schema.json", to)
+		}
+		return parts[1] + u + `"`
+	})
+	schema = schemaProperty("version").ReplaceAllStringFunc(schema, func(m string) string {
+		return replaceVersion(m, `("const"\s*:\s*)%d\b`, "${1}%d", from, to)
+	})
+	schema = schemaProperty("schema_version").ReplaceAllStringFunc(schema, func(m string) string {
+		return replaceVersion(m, `"%d\.\d+"`, `"%d.0"`, from, to)
+	})
+	return schema
+}
+
+// bumpProto updates the package of a protobuf schema and the version in its
+// comments.
+func bumpProto(proto string, from, to int) string {
+	proto = replaceVersion(proto, `(?m)^(package .*\.)v%d;`, "${1}v%d;", from, to)
+	return replaceVersion(proto, `(payload, always )%d\b`, "${1}%d", from, to)
+}
+
+// bumpAvro updates the namespace of an Avro schema, and the default version
+// and schema_version of its metadata.
+func bumpAvro(avsc string, from, to int) string {
+	avsc = replaceVersion(avsc, `("namespace"\s*:\s*"[^"]*\.)v%d"`, `${1}v%d"`, from, to)
+	version := regexp.MustCompile(`\{[^{}]*"name"\s*:\s*"version"[^{}]*\}`)
+	avsc = version.ReplaceAllStringFunc(avsc, func(m string) string {
+		return replaceVersion(m, `("default"\s*:\s*)%d\b`, "${1}%d", from, to)
+	})
+	schemaVersion := regexp.MustCompile(`\{[^{}]*"name"\s*:\s*"schema_version"[^{}]*\}`)
+	return schemaVersion.ReplaceAllStringFunc(avsc, func(m string) string {
+		return replaceVersion(m, `("default"\s*:\s*")%d\.\d+"`, `${1}%d.0"`, from, to)
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBumpMarkdown(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			name: "changelog before payload schema",
+			in: "---\nsubject: order.created\nversion: 1\n---\n# Event: order.created\n**Version**: 1\n\n" +
+				"## Payload Schema\nSee [v1.schema.json](./v1.schema.json) and [v1.proto](./v1.proto), not v10.md.\n\n" +
+				"## Example Payload\n```json\n{\"version\": 1, \"schema_version\": \"1.2\", \"count\": 1}\n```\n",
+			want: "---\nsubject: order.created\nversion: 2\n---\n# Event: order.created\n**Version**: 2\n\n" +
+				"## Changelog\n### v2\n- _TODO: Describe what changed since v1._\n\n" +
+				"## Payload Schema\nSee [v2.schema.json](./v2.schema.json) and [v2.proto](./v2.proto), not v10.md.\n\n" +
+				"## Example Payload\n```json\n{\"version\": 2, \"schema_version\": \"2.0\", \"count\": 1}\n```\n",
+		},
+		{
+			name: "existing changelog",
+			in:   "**Version**: v1\n\n## Changelog\n\n### v1\n- Added totals.\n",
+			want: "**Version**: v2\n\n## Changelog\n\n### v2\n- _TODO: Describe what changed since v1._\n\n### v1\n- Added totals.\n",
+		},
+		{
+			name: "no payload schema section",
+			in:   "**Version**: 1\n\n",
+			want: "**Version**: 2\n\n## Changelog\n### v2\n- _TODO: Describe what changed since v1._\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bumpMarkdown(tt.in, 1, 2); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumpJSONSchema(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			name: "versioned $id",
+			in:   `{"$id": "https://example.com/schemas/order-created-event/v1.schema.json"}`,
+			want: `{"$id": "https://example.com/schemas/order-created-event/v2.schema.json"}`,
+		},
+		{
+			name: "unversioned $id",
+			in:   `{"$id": "https://example.com/schemas/order-created-event.schema.json"}`,
+			want: `{"$id": "https://example.com/schemas/order-created-event/v2.schema.json"}`,
+		},
+		{
+			name: "version const and schema_version default",
+			in: `{"properties": {
+  "version": {"type": "integer", "const": 1},
+  "schema_version": {"type": "string", "default": "1.3"},
+  "count": {"type": "integer", "const": 1}
+}}`,
+			want: `{"properties": {
+  "version": {"type": "integer", "const": 2},
+  "schema_version": {"type": "string", "default": "2.0"},
+  "count": {"type": "integer", "const": 1}
+}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bumpJSONSchema(tt.in, 1, 2); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumpProto(t *testing.T) {
+	in := "syntax = \"proto3\";\n\npackage events.order.created.v1;\n\n" +
+		"message EventMetadata {\n  // Version of the event payload, always 1\n  int32 version = 3;\n  int32 count = 4; // always 1\n}\n"
+	want := "syntax = \"proto3\";\n\npackage events.order.created.v2;\n\n" +
+		"message EventMetadata {\n  // Version of the event payload, always 2\n  int32 version = 3;\n  int32 count = 4; // always 1\n}\n"
+	if got := bumpProto(in, 1, 2); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBumpAvro(t *testing.T) {
+	in := `{"namespace": "events.order.created.v1", "fields": [
+  {"name": "version", "type": "int", "default": 1},
+  {"name": "schema_version", "type": "string", "default": "1.1"},
+  {"name": "count", "type": "int", "default": 1}
+]}`
+	want := `{"namespace": "events.order.created.v2", "fields": [
+  {"name": "version", "type": "int", "default": 2},
+  {"name": "schema_version", "type": "string", "default": "2.0"},
+  {"name": "count", "type": "int", "default": 1}
+]}`
+	if got := bumpAvro(in, 1, 2); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTodoLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"# Event\n_TODO: describe_\n\n- TODO\n", []string{"2", "4"}},
+		{"# Event\nDone.\n", nil},
+	}
+	for _, tt := range tests {
+		if got := todoLines(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("todoLines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
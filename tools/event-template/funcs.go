@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// templateFuncs are the functions available to templates.
+var templateFuncs = template.FuncMap{
+	"inc":    func(i int) int { return i + 1 },
+	"snake":  snake,
+	"kebab":  kebab,
+	"camel":  camel,
+	"pascal": pascal,
+	"title":  title,
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+	"join":   func(sep string, s []string) string { return strings.Join(s, sep) },
+}
+
+// words splits s into lowercase words at anything but letters and digits,
+// and where a capital follows a lowercase letter or digit, so that
+// "order.{order_id}", "OrderID" and "order-id" are all "order" and "id".
+func words(s string) []string {
+	var words []string
+	var word []rune
+	prev := rune(0)
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			if len(word) > 0 && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+				words = append(words, string(word))
+				word = nil
+			}
+			word = append(word, unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		prev = r
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// capitalize returns word with its first letter in upper case.
+func capitalize(word string) string {
+	r := []rune(word)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// snake returns s in snake_case, e.g. "order_created".
+func snake(s string) string {
+	return strings.Join(words(s), "_")
+}
+
+// kebab returns s in kebab-case, e.g. "order-created".
+func kebab(s string) string {
+	return strings.Join(words(s), "-")
+}
+
+// camel returns s in camelCase, e.g. "orderCreated".
+func camel(s string) string {
+	p := pascal(s)
+	if p == "" {
+		return ""
+	}
+	r := []rune(p)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// pascal returns s in PascalCase, e.g. "OrderCreated".
+func pascal(s string) string {
+	var b strings.Builder
+	for _, word := range words(s) {
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+// title returns s as capitalized words, e.g. "Order Created".
+func title(s string) string {
+	ws := words(s)
+	for i, word := range ws {
+		ws[i] = capitalize(word)
+	}
+	return strings.Join(ws, " ")
+}
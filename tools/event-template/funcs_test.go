@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"order.created", []string{"order", "created"}},
+		{"order.{order_id}", []string{"order", "order", "id"}},
+		{"OrderID", []string{"order", "id"}},
+		{"orderV2Created", []string{"order", "v2", "created"}},
+		{"order--id", []string{"order", "id"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := words(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCases(t *testing.T) {
+	tests := []struct {
+		in                                     string
+		snake, kebab, camel, pascal, titleCase string
+	}{
+		{"order.created", "order_created", "order-created", "orderCreated", "OrderCreated", "Order Created"},
+		{"order.{order_id}", "order_order_id", "order-order-id", "orderOrderId", "OrderOrderId", "Order Order Id"},
+		{"OrderID", "order_id", "order-id", "orderId", "OrderId", "Order Id"},
+		{"", "", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			name string
+			f    func(string) string
+			want string
+		}{
+			{name: "snake", f: snake, want: tt.snake},
+			{name: "kebab", f: kebab, want: tt.kebab},
+			{name: "camel", f: camel, want: tt.camel},
+			{name: "pascal", f: pascal, want: tt.pascal},
+			{name: "title", f: title, want: tt.titleCase},
+		} {
+			if got := c.f(tt.in); got != c.want {
+				t.Errorf("%s(%q) = %q, want %q", c.name, tt.in, got, c.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"catalog"
+)
+
+const (
+	// archiveDir is the default directory retired versions are moved to.
+	archiveDir = "archive"
+	// noticePrefix starts the line of the docs telling a version is no
+	// longer active.
+	noticePrefix = "> ⚠️ **"
+)
+
+// parseArgs parses the flags of a command, and exits with its usage unless
+// n arguments are left.
+func parseArgs(flags *flag.FlagSet, args []string, n int) {
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != n {
+		flags.Usage()
+		os.Exit(1)
+	}
+}
+
+// parseSubjectVersion parses the subject and version arguments of a
+// command, and returns the path of the docs of the version.
+func parseSubjectVersion(flags *flag.FlagSet, maxDepth int) (catalog.Subject, int, string) {
+	subject, err := catalog.ParseSubject(flags.Arg(0), maxDepth)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	version, err := catalog.ParseVersion(flags.Arg(1))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	mdPath := filepath.Join(subject.Dir("events"), fmt.Sprintf("v%d.md", version))
+	if _, err := os.Stat(mdPath); err != nil {
+		fatalf("No docs for v%d of %s: %v", version, subject, err)
+	}
+	return subject, version, mdPath
+}
+
+// versionFiles returns the names of the docs and schema files of a version
+// in dir.
+func versionFiles(dir string, version int) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fatalf("Failed to read %s: %v", dir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		m := versionFile.FindStringSubmatch(entry.Name())
+		if m != nil && !entry.IsDir() && m[1] == strconv.Itoa(version) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readDoc reads the docs of a version and their front matter, which is
+// filled in from the subject and version if the docs have none.
+func readDoc(path string, subject catalog.Subject, version int) (catalog.Metadata, string) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		fatalf("Failed to read %s: %v", path, err)
+	}
+	meta, body, err := catalog.ParseDoc(string(b))
+	if err != nil {
+		fatalf("%s: %v", path, err)
+	}
+	if meta.Subject == "" {
+		meta.Subject = subject.String()
+	}
+	if meta.Version == 0 {
+		meta.Version = version
+	}
+	return meta, body
+}
+
+// writeDoc writes docs with their front matter.
+func writeDoc(path string, meta catalog.Metadata, body string) {
+	if err := os.WriteFile(path, []byte(meta.FrontMatter()+body), 0644); err != nil {
+		fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// setNotice replaces the notice of the docs body telling the version is no
+// longer active, or adds it before the first section.
+func setNotice(body, text string) string {
+	body = removeNotice(body)
+	text = noticePrefix + text + "\n\n"
+	if i := strings.Index(body, "\n## "); i >= 0 {
+		return body[:i+1] + text + body[i+1:]
+	}
+	return body + "\n" + text
+}
+
+// removeNotice removes the notice of the docs body telling the version is no
+// longer active, if any.
+func removeNotice(body string) string {
+	var lines []string
+	notice := false
+	for _, line := range strings.SplitAfter(body, "\n") {
+		// drop the notice and the blank line after it
+		if strings.HasPrefix(line, noticePrefix) || notice && line == "\n" {
+			notice = strings.HasPrefix(line, noticePrefix)
+			continue
+		}
+		notice = false
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "")
+}
+
+// regenerateIndex writes the files generate-index writes by default again,
+// and the YAML manifest if there is one.
+func regenerateIndex(maxDepth int) {
+	events, err := catalog.Walk("events", maxDepth)
+	if err != nil {
+		fatalf("Failed to regenerate the catalog: %v", err)
+	}
+	outputs := catalog.DefaultOutputs
+	if _, err := os.Stat(outputs.YAMLPath()); err == nil {
+		outputs.YAML = true
+	}
+	written, err := outputs.Write(events)
+	for _, path := range written {
+		fmt.Printf("✅ Regenerated %s\n", path)
+	}
+	if err != nil {
+		fatalf("Failed to regenerate the catalog: %v", err)
+	}
+}
+
+// deprecate marks a version of an event deprecated until its sunset date.
+func deprecate(args []string) {
+	flags := flag.NewFlagSet("deprecate", flag.ExitOnError)
+	sunset := flags.String("sunset", "", "date the version will be retired, e.g. 2025-06-30 (required)")
+	replacedBy := flags.String("replaced-by", "", `what replaces the version, e.g. "order.{order_id}.placed v1"`)
+	maxDepth := flags.Int("max-depth", catalog.DefaultMaxDepth, "maximum number of tokens of a subject")
+	parseArgs(flags, args, 2)
+	subject, version, mdPath := parseSubjectVersion(flags, *maxDepth)
+	if _, err := time.Parse(time.DateOnly, *sunset); err != nil {
+		fatalf("Sunset must be a date like 2025-06-30, got %q", *sunset)
+	}
+
+	meta, body := readDoc(mdPath, subject, version)
+	if meta.Status != "" && meta.Status != catalog.StatusDeprecated {
+		fatalf("v%d of %s is already %s", version, subject, meta.Status)
+	}
+	meta.Status = catalog.StatusDeprecated
+	meta.Sunset = *sunset
+	if *replacedBy != "" {
+		meta.ReplacedBy = *replacedBy
+	}
+
+	notice := fmt.Sprintf("Deprecated**: this version will be retired on %s.", *sunset)
+	if meta.ReplacedBy != "" {
+		notice += fmt.Sprintf(" Use %s instead.", meta.ReplacedBy)
+	}
+	writeDoc(mdPath, meta, setNotice(body, notice))
+	fmt.Printf("✅ Deprecated v%d of %s until %s\n", version, subject, *sunset)
+	regenerateIndex(*maxDepth)
+}
+
+// redirects returns the docs rename left at old subjects redirecting to a
+// version of subject, directly or through other redirects.
+func redirects(subject catalog.Subject, version, maxDepth int) []catalog.Event {
+	events, err := catalog.Walk("events", maxDepth)
+	if err != nil {
+		fatalf("Failed to read the catalog: %v", err)
+	}
+	targets := map[string]bool{subject.String(): true}
+	var stubs []catalog.Event
+	for found := true; found; {
+		found = false
+		for _, event := range events {
+			if event.Version == version && event.Metadata.Status == catalog.StatusRenamed &&
+				targets[event.Metadata.RenamedTo] && !targets[event.Subject.String()] {
+				targets[event.Subject.String()] = true
+				stubs = append(stubs, event)
+				found = true
+			}
+		}
+	}
+	return stubs
+}
+
+// retire moves the docs and schema of a retired version of an event to the
+// archive directory, along with the redirects to it left by rename, whose
+// links keep working there.
+func retire(args []string) {
+	flags := flag.NewFlagSet("retire", flag.ExitOnError)
+	archive := flags.String("archive", archiveDir, "directory retired versions are moved to")
+	maxDepth := flags.Int("max-depth", catalog.DefaultMaxDepth, "maximum number of tokens of a subject")
+	parseArgs(flags, args, 2)
+	subject, version, mdPath := parseSubjectVersion(flags, *maxDepth)
+
+	dirPath := subject.Dir("events")
+	archivePath := subject.Dir(*archive)
+	names := versionFiles(dirPath, version)
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(archivePath, name)); err == nil {
+			fatalf("File already exists: %s", filepath.Join(archivePath, name))
+		}
+	}
+	stubs := redirects(subject, version, *maxDepth)
+	archived := func(stub catalog.Event) string {
+		return filepath.Join(stub.Subject.Dir(*archive), filepath.Base(stub.Path))
+	}
+	for _, stub := range stubs {
+		if _, err := os.Stat(archived(stub)); err == nil {
+			fatalf("File already exists: %s", archived(stub))
+		}
+	}
+
+	meta, body := readDoc(mdPath, subject, version)
+	if meta.Status != catalog.StatusDeprecated {
+		fmt.Printf("⚠️  v%d of %s was not deprecated before being retired\n", version, subject)
+	}
+	meta.Status = catalog.StatusRetired
+	meta.Retired = time.Now().Format(time.DateOnly)
+
+	if err := os.MkdirAll(archivePath, 0755); err != nil {
+		fatalf("Failed to create directory: %v", err)
+	}
+	for _, name := range names {
+		from, to := filepath.Join(dirPath, name), filepath.Join(archivePath, name)
+		if from == mdPath {
+			writeDoc(to, meta, setNotice(body, fmt.Sprintf("Retired** on %s.", meta.Retired)))
+			if err := os.Remove(from); err != nil {
+				fatalf("Failed to remove %s: %v", from, err)
+			}
+		} else if err := os.Rename(from, to); err != nil {
+			fatalf("Failed to move %s: %v", from, err)
+		}
+		fmt.Printf("✅ Moved %s to %s\n", from, to)
+	}
+	removeEmptyDirs(dirPath, "events")
+	for _, stub := range stubs {
+		from, to := filepath.FromSlash(stub.Path), archived(stub)
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.Rename(from, to); err != nil {
+			fatalf("Failed to move %s: %v", from, err)
+		}
+		fmt.Printf("✅ Moved the redirect %s to %s\n", from, to)
+		removeEmptyDirs(filepath.Dir(from), "events")
+	}
+	regenerateIndex(*maxDepth)
+}
+
+// removeEmptyDirs removes dir and its parents up to root while they are
+// empty.
+func removeEmptyDirs(dir, root string) {
+	for dir != root && dir != "." && os.Remove(dir) == nil {
+		dir = filepath.Dir(dir)
+	}
+}
+
+// renamer returns a function replacing the names derived from the subject
+// of an event in its docs and schemas with those of its new subject.
+func renamer(from, to catalog.Subject) func(string) string {
+	fromType, toType := strings.Join(from.Types(), "."), strings.Join(to.Types(), ".")
+	replacer := strings.NewReplacer(
+		from.String(), to.String(),
+		"events."+fromType+".v", "events."+toType+".v",
+		"/"+strings.Join(from.Types(), "-")+"-event", "/"+strings.Join(to.Types(), "-")+"-event",
+		pascal(fromType)+"Event", pascal(toType)+"Event",
+		pascal(fromType)+"Data", pascal(toType)+"Data",
+	)
+	// the event type on its own, e.g. "order.created" but not
+	// "events.order.created.v1"
+	eventType := regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(fromType) + `($|[^\w.])`)
+	return func(s string) string {
+		return eventType.ReplaceAllString(replacer.Replace(s), "${1}"+toType+"${2}")
+	}
+}
+
+// rename moves all versions of an event to a new subject, and leaves docs at
+// the old subject redirecting to the new one.
+func rename(args []string) {
+	flags := flag.NewFlagSet("rename", flag.ExitOnError)
+	maxDepth := flags.Int("max-depth", catalog.DefaultMaxDepth, "maximum number of tokens of a subject")
+	parseArgs(flags, args, 2)
+	from, err := catalog.ParseSubject(flags.Arg(0), *maxDepth)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	to, err := catalog.ParseSubject(flags.Arg(1), *maxDepth)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	fromDir, toDir := from.Dir("events"), to.Dir("events")
+
+	entries, err := os.ReadDir(fromDir)
+	if err != nil {
+		fatalf("Failed to read %s: %v", fromDir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if versionFile.MatchString(entry.Name()) && !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		fatalf("No versions of %s found in %s", from, fromDir)
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(toDir, name)); err == nil {
+			fatalf("File already exists: %s", filepath.Join(toDir, name))
+		}
+		if version, err := catalog.ParseVersion(strings.TrimSuffix(name, ".md")); err == nil {
+			if meta, _ := readDoc(filepath.Join(fromDir, name), from, version); meta.Status == catalog.StatusRenamed {
+				fatalf("%s was already renamed to %s", from, meta.RenamedTo)
+			}
+		}
+	}
+
+	// the redirect stubs link to the new docs relative to the old ones
+	rel, err := filepath.Rel(fromDir, toDir)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	replace := renamer(from, to)
+	if err := os.MkdirAll(toDir, 0755); err != nil {
+		fatalf("Failed to create directory: %v", err)
+	}
+	for _, name := range names {
+		fromPath, toPath := filepath.Join(fromDir, name), filepath.Join(toDir, name)
+		if !strings.HasSuffix(name, ".md") {
+			b, err := os.ReadFile(fromPath)
+			if err != nil {
+				fatalf("Failed to read %s: %v", fromPath, err)
+			}
+			if err := os.WriteFile(toPath, []byte(replace(string(b))), 0644); err != nil {
+				fatalf("Failed to write %s: %v", toPath, err)
+			}
+			if err := os.Remove(fromPath); err != nil {
+				fatalf("Failed to remove %s: %v", fromPath, err)
+			}
+			fmt.Printf("✅ Moved %s to %s\n", fromPath, toPath)
+			continue
+		}
+
+		version, _ := catalog.ParseVersion(strings.TrimSuffix(name, ".md"))
+		meta, body := readDoc(fromPath, from, version)
+		moved := meta
+		moved.Subject = to.String()
+		moved.ReplacedBy = replace(moved.ReplacedBy)
+		writeDoc(toPath, moved, replace(body))
+		fmt.Printf("✅ Moved %s to %s\n", fromPath, toPath)
+
+		stub := catalog.Metadata{
+			Subject:   from.String(),
+			Version:   version,
+			Owner:     meta.Owner,
+			Status:    catalog.StatusRenamed,
+			RenamedTo: to.String(),
+		}
+		link := filepath.ToSlash(filepath.Join(rel, name))
+		writeDoc(fromPath, stub, fmt.Sprintf("# Event: %s\n**Version**: %d\n\n%sRenamed** to [`%s`](%s).\n",
+			from, version, noticePrefix, to, link))
+		fmt.Printf("✅ Left a redirect to %s in %s\n", to, fromPath)
+	}
+	regenerateIndex(*maxDepth)
+}
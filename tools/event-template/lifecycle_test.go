@@ -0,0 +1,238 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"catalog"
+)
+
+// inTempDir changes to a temporary directory holding files, by their path
+// relative to it, for the duration of the test.
+func inTempDir(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	for name, content := range files {
+		path := filepath.FromSlash(name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// exitCode is what exit panics with in tests.
+type exitCode int
+
+// exits reports whether f calls fatalf.
+func exits(t *testing.T, f func()) (exited bool) {
+	t.Helper()
+	exit = func(code int) { panic(exitCode(code)) }
+	defer func() {
+		exit = os.Exit
+		if r := recover(); r != nil {
+			if _, ok := r.(exitCode); !ok {
+				panic(r)
+			}
+			exited = true
+		}
+	}()
+	f()
+	return false
+}
+
+// readFile returns the content of a file, failing the test if it cannot be
+// read.
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.FromSlash(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestSetNotice(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{
+			name: "insert before first section",
+			body: "# Event: order.created\n**Version**: 1\n\n## Description\nAn order.\n\n\n## Subject\n",
+			want: "# Event: order.created\n**Version**: 1\n\n> ⚠️ **Retired** on 2025-01-01.\n\n## Description\nAn order.\n\n\n## Subject\n",
+		},
+		{
+			name: "replace",
+			body: "# Event\n\n> ⚠️ **Deprecated**: this version will be retired on 2024-12-31.\n\n## Description\n",
+			want: "# Event\n\n> ⚠️ **Retired** on 2025-01-01.\n\n## Description\n",
+		},
+		{
+			name: "no sections",
+			body: "# Event\n",
+			want: "# Event\n\n> ⚠️ **Retired** on 2025-01-01.\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setNotice(tt.body, "Retired** on 2025-01-01."); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenamer(t *testing.T) {
+	from, _ := catalog.ParseSubject("order.{order_id}.placed", catalog.DefaultMaxDepth)
+	to, _ := catalog.ParseSubject("order.{order_id}.submitted", catalog.DefaultMaxDepth)
+	replace := renamer(from, to)
+	tests := []struct{ in, want string }{
+		{`"$id": "https://example.com/schemas/order-placed-event/v1.schema.json"`, `"$id": "https://example.com/schemas/order-submitted-event/v1.schema.json"`},
+		{`"$id": "https://example.com/schemas/order-placed-event.schema.json"`, `"$id": "https://example.com/schemas/order-submitted-event.schema.json"`},
+		{`"title": "OrderPlacedEvent"`, `"title": "OrderSubmittedEvent"`},
+		{`"name": "OrderPlacedData"`, `"name": "OrderSubmittedData"`},
+		{`"event_type": {"type": "string", "enum": ["order.placed"]}`, `"event_type": {"type": "string", "enum": ["order.submitted"]}`},
+		{"// Type of the event, always order.placed", "// Type of the event, always order.submitted"},
+		{"package events.order.placed.v1;", "package events.order.submitted.v1;"},
+		{"# Event: order.{order_id}.placed", "# Event: order.{order_id}.submitted"},
+		// other events are left alone
+		{`"enum": ["order.placed_late", "myorder.placed"]`, `"enum": ["order.placed_late", "myorder.placed"]`},
+	}
+	for _, tt := range tests {
+		if got := replace(tt.in); got != tt.want {
+			t.Errorf("renamer(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRename(t *testing.T) {
+	inTempDir(t, map[string]string{
+		"events/order/{order_id}/placed/v1.md":          "---\nsubject: order.{order_id}.placed\nversion: 1\nowner: team-orders\n---\n# Event: order.{order_id}.placed\n",
+		"events/order/{order_id}/placed/v1.schema.json": `{"title": "OrderPlacedEvent", "enum": ["order.placed"]}`,
+		"events/order/{order_id}/placed/v2.md":          "# Event: order.{order_id}.placed\n",
+	})
+	deprecate([]string{"-sunset", "2025-06-30", "-replaced-by", "order.{order_id}.placed v2", "order.{order_id}.placed", "v1"})
+	rename([]string{"order.{order_id}.placed", "order.{order_id}.checkout.submitted"})
+	// the subjects need not have the same depth
+	const toDir = "events/order/{order_id}/checkout/submitted"
+
+	if got, want := readFile(t, toDir+"/v1.schema.json"), `{"title": "OrderCheckoutSubmittedEvent", "enum": ["order.checkout.submitted"]}`; got != want {
+		t.Errorf("got schema %s, want %s", got, want)
+	}
+	meta, body, err := catalog.ParseDoc(readFile(t, toDir+"/v1.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Subject != "order.{order_id}.checkout.submitted" || meta.Owner != "team-orders" || meta.Status != catalog.StatusDeprecated {
+		t.Errorf("got moved front matter %+v", meta)
+	}
+	// what replaces the version was renamed too
+	if want := "order.{order_id}.checkout.submitted v2"; meta.ReplacedBy != want {
+		t.Errorf("got replaced_by %q, want %q", meta.ReplacedBy, want)
+	}
+	if want := "# Event: order.{order_id}.checkout.submitted\n\n" + noticePrefix + "Deprecated**: this version will be retired on 2025-06-30. Use order.{order_id}.checkout.submitted v2 instead.\n\n"; body != want {
+		t.Errorf("got moved body %q, want %q", body, want)
+	}
+
+	for _, name := range []string{"v1.md", "v2.md"} {
+		stubPath := "events/order/{order_id}/placed/" + name
+		meta, body, err := catalog.ParseDoc(readFile(t, stubPath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.Status != catalog.StatusRenamed || meta.RenamedTo != "order.{order_id}.checkout.submitted" {
+			t.Errorf("%s: got front matter %+v", stubPath, meta)
+		}
+		link := body[strings.LastIndex(body, "](")+2 : strings.LastIndex(body, ")")]
+		if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.FromSlash(stubPath)), filepath.FromSlash(link))); err != nil {
+			t.Errorf("%s: link %s does not work: %v", stubPath, link, err)
+		}
+	}
+	if _, err := os.Stat("events/order/{order_id}/placed/v1.schema.json"); err == nil {
+		t.Error("the schema was left at the old subject")
+	}
+	for _, name := range []string{"index.md", "catalog.json", "graph.mmd", "graph.dot"} {
+		if got := readFile(t, name); !strings.Contains(got, "order.{order_id}.checkout.submitted") {
+			t.Errorf("%s was not regenerated:\n%s", name, got)
+		}
+	}
+
+	if !exits(t, func() { rename([]string{"order.{order_id}.placed", "order.{order_id}.sent"}) }) {
+		t.Error("renaming an already renamed subject did not fail")
+	}
+}
+
+func TestRetire(t *testing.T) {
+	inTempDir(t, map[string]string{
+		"events/order/{order_id}/placed/v1.md":          "---\nstatus: deprecated\nsunset: \"2025-06-30\"\n---\n# Event\n\n> ⚠️ **Deprecated**: gone soon.\n\n## Description\n",
+		"events/order/{order_id}/placed/v1.schema.json": "{}",
+		"events/user/{user_id}/joined/v1.md":            "# Event\n",
+	})
+	retire([]string{"order.{order_id}.placed", "v1"})
+
+	meta, body, err := catalog.ParseDoc(readFile(t, "archive/order/{order_id}/placed/v1.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Status != catalog.StatusRetired || meta.Retired == "" || meta.Sunset != "2025-06-30" {
+		t.Errorf("got front matter %+v", meta)
+	}
+	if want := "# Event\n\n> ⚠️ **Retired** on " + meta.Retired + ".\n\n## Description\n"; body != want {
+		t.Errorf("got body %q, want %q", body, want)
+	}
+	if got := readFile(t, "archive/order/{order_id}/placed/v1.schema.json"); got != "{}" {
+		t.Errorf("got schema %q", got)
+	}
+	// the directories of the subject are removed, but not events itself
+	if _, err := os.Stat("events/order"); !os.IsNotExist(err) {
+		t.Errorf("events/order was not removed: %v", err)
+	}
+	if _, err := os.Stat("events/user/{user_id}/joined/v1.md"); err != nil {
+		t.Error(err)
+	}
+	if index := readFile(t, "index.md"); strings.Contains(index, "placed") {
+		t.Errorf("index.md still lists the retired version:\n%s", index)
+	}
+}
+
+func TestRetireRenamed(t *testing.T) {
+	inTempDir(t, map[string]string{
+		"events/order/{order_id}/created/v1.md": "# Event: order.{order_id}.created\n",
+		"events/order/{order_id}/created/v2.md": "# Event: order.{order_id}.created\n",
+	})
+	rename([]string{"order.{order_id}.created", "order.{order_id}.placed"})
+	rename([]string{"order.{order_id}.placed", "order.{order_id}.submitted"})
+	retire([]string{"order.{order_id}.submitted", "v1"})
+
+	// the redirects to v1, through placed too, are archived with it and
+	// still link to its docs
+	for _, stubPath := range []string{"archive/order/{order_id}/created/v1.md", "archive/order/{order_id}/placed/v1.md"} {
+		body := readFile(t, stubPath)
+		link := body[strings.LastIndex(body, "](")+2 : strings.LastIndex(body, ")")]
+		if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.FromSlash(stubPath)), filepath.FromSlash(link))); err != nil {
+			t.Errorf("%s: link %s does not work: %v", stubPath, link, err)
+		}
+	}
+	for _, name := range []string{"created/v1.md", "placed/v1.md"} {
+		if _, err := os.Stat("events/order/{order_id}/" + name); !os.IsNotExist(err) {
+			t.Errorf("the redirect %s was left: %v", name, err)
+		}
+	}
+	// those to v2 are left alone
+	for _, name := range []string{"created/v2.md", "placed/v2.md", "submitted/v2.md"} {
+		if _, err := os.Stat("events/order/{order_id}/" + name); err != nil {
+			t.Error(err)
+		}
+	}
+}
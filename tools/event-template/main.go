@@ -1,70 +1,242 @@
 package main
 
 import (
+	"bytes"
 	"embed"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
+
+	"catalog"
 )
 
-//go:embed template/event.md.tmpl
+//go:embed template/*.tmpl
 var tmplFS embed.FS
 
+// templateDir is the default directory of templates overriding the embedded
+// ones, relative to the root of the repository.
+const templateDir = ".event-template"
+
+// schemaBaseURL prefixes the $id of generated JSON schemas.
+const schemaBaseURL = "https://example.com/schemas/"
+
+// schemaFormats maps the -format values to the extension of the schema file
+// and its template.
+var schemaFormats = map[string]struct{ ext, template string }{
+	"json":  {"schema.json", "schema.json.tmpl"},
+	"proto": {"proto", "schema.proto.tmpl"},
+	"avro":  {"avsc", "schema.avsc.tmpl"},
+}
+
+// EventTemplateData is the data templates are executed with.
 type EventTemplateData struct {
 	Subject string
 	Version string
+	// Tokens are the tokens of the subject, e.g. "order", "{order_id}"
+	// and "created".
+	Tokens []string
+	// Domain is the first token of the subject, e.g. "order".
+	Domain string
+	// EventType is the subject without its {param} tokens, e.g.
+	// "order.created".
+	EventType string
+	// Params are the names of the {param} tokens of the subject.
+	Params []string
+	// Name is the event type in PascalCase, e.g. "OrderCreated".
+	Name string
+	// Package is the protobuf package or Avro namespace of the schema,
+	// e.g. "events.order.created.v1".
+	Package string
+	// SchemaID is the $id of a JSON schema.
+	SchemaID string
+	// SchemaFile is the name of the schema file, e.g. "v1.schema.json".
+	SchemaFile string
+	// Date is the day the docs are created, e.g. "2024-03-19".
+	Date string
+	// Metadata holds the description, owner, producers, consumers and
+	// tags given as flags.
+	catalog.Metadata
+	// FrontMatter is Metadata as the YAML front matter of the docs.
+	FrontMatter string
+}
+
+func newEventTemplateData(subject catalog.Subject, version, schemaExt string, meta catalog.Metadata) EventTemplateData {
+	types := subject.Types()
+	data := EventTemplateData{
+		Subject:     subject.String(),
+		Version:     version,
+		Tokens:      subject.Tokens,
+		Domain:      subject.Domain(),
+		EventType:   strings.Join(types, "."),
+		Params:      subject.Params(),
+		Name:        pascal(strings.Join(types, ".")),
+		SchemaFile:  "v" + version + "." + schemaExt,
+		Date:        time.Now().Format(time.DateOnly),
+		Metadata:    meta,
+		FrontMatter: meta.FrontMatter(),
+	}
+	data.Package = "events." + data.EventType + ".v" + version
+	data.SchemaID = schemaBaseURL + strings.Join(types, "-") + "-event/v" + version + ".schema.json"
+	return data
+}
+
+// listFlag is a flag which may be given several times.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
 }
 
+const usage = `Usage:
+  %[1]s [-format json|proto|avro] [-max-depth n] [-templates dir] [-description text]
+      [-owner team] [-producer service]... [-consumer service]... [-tag tag]...
+      <subject> <version>
+        Create the docs and a starter schema for a new event.
+  %[1]s bump [-max-depth n] <subject>
+        Copy the latest version of an event to the next version.
+  %[1]s deprecate -sunset YYYY-MM-DD [-replaced-by text] [-max-depth n] <subject> <version>
+        Mark a version of an event deprecated until its sunset date.
+  %[1]s retire [-archive dir] [-max-depth n] <subject> <version>
+        Move a retired version of an event to the archive.
+  %[1]s rename [-max-depth n] <subject> <new subject>
+        Move all versions of an event to a new subject, leaving redirect stubs.
+
+Subjects are lowercase tokens separated by dots, some of which may be
+{snake_case} parameters, e.g. order.{order_id}.created. Versions are like v1.
+`
+
 func main() {
-	if len(os.Args) != 3 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <subject> <version>\n", os.Args[0])
-		os.Exit(1)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bump":
+			bump(os.Args[2:])
+			return
+		case "deprecate":
+			deprecate(os.Args[2:])
+			return
+		case "retire":
+			retire(os.Args[2:])
+			return
+		case "rename":
+			rename(os.Args[2:])
+			return
+		}
 	}
+	create(os.Args[1:])
+}
 
-	subject := os.Args[1]
-	version := os.Args[2]
+// exit is os.Exit, which tests replace to catch fatalf.
+var exit = os.Exit
 
-	parts := strings.Split(subject, ".")
-	if len(parts) < 2 {
-		fmt.Fprintln(os.Stderr, "Subject must be like item.added or foo.bar")
+// fatalf prints an error and exits.
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	exit(1)
+}
+
+// create writes the docs and a starter schema for a new event.
+func create(args []string) {
+	flags := flag.NewFlagSet("event-template", flag.ExitOnError)
+	format := flags.String("format", "json", `schema format: "json" (JSON Schema), "proto" or "avro"`)
+	maxDepth := flags.Int("max-depth", catalog.DefaultMaxDepth, "maximum number of tokens of a subject")
+	templates := flags.String("templates", templateDir, "directory of templates overriding the embedded ones, if it exists")
+	var meta catalog.Metadata
+	flags.StringVar(&meta.Description, "description", "", "what the event represents")
+	flags.StringVar(&meta.Owner, "owner", "", "team owning the event")
+	flags.Var((*listFlag)(&meta.Producers), "producer", "service emitting the event; may be repeated")
+	flags.Var((*listFlag)(&meta.Consumers), "consumer", "service consuming the event; may be repeated")
+	flags.Var((*listFlag)(&meta.Tags), "tag", "tag of the event; may be repeated")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
 		os.Exit(1)
 	}
 
-	dirPath := filepath.Join(append([]string{"events"}, parts...)...)
-	mdPath := filepath.Join(dirPath, "v"+version+".md")
+	subject, err := catalog.ParseSubject(flags.Arg(0), *maxDepth)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	n, err := catalog.ParseVersion(flags.Arg(1))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	version := strconv.Itoa(n)
+	meta.Subject = subject.String()
+	meta.Version = n
+	schemaFormat, ok := schemaFormats[*format]
+	if !ok {
+		fatalf("Unknown schema format %q, must be json, proto or avro", *format)
+	}
 
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create directory: %v\n", err)
-		os.Exit(1)
+	dirPath := subject.Dir("events")
+	data := newEventTemplateData(subject, version, schemaFormat.ext, meta)
+	files := []struct {
+		path, template string
+		content        bytes.Buffer
+	}{
+		{path: filepath.Join(dirPath, "v"+version+".md"), template: "event.md.tmpl"},
+		{path: filepath.Join(dirPath, data.SchemaFile), template: schemaFormat.template},
 	}
 
-	if _, err := os.Stat(mdPath); err == nil {
-		fmt.Fprintf(os.Stderr, "File already exists: %s\n", mdPath)
-		os.Exit(1)
+	for _, file := range files {
+		if _, err := os.Stat(file.path); err == nil {
+			fatalf("File already exists: %s", file.path)
+		}
 	}
 
-	templateContent, err := tmplFS.ReadFile("template/event.md.tmpl")
+	tmpl, err := loadTemplates(*templates)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load template: %v\n", err)
-		os.Exit(1)
+		fatalf("Failed to load templates: %v", err)
 	}
 
-	tmpl := template.Must(template.New("event").Parse(string(templateContent)))
+	for i := range files {
+		if err := tmpl.ExecuteTemplate(&files[i].content, files[i].template, data); err != nil {
+			fatalf("Failed to execute template %s: %v", files[i].template, err)
+		}
+	}
 
-	f, err := os.Create(mdPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to write markdown file: %v\n", err)
-		os.Exit(1)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		fatalf("Failed to create directory: %v", err)
 	}
-	defer f.Close()
 
-	err = tmpl.Execute(f, EventTemplateData{Subject: subject, Version: version})
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to execute template: %v\n", err)
-		os.Exit(1)
+	for _, file := range files {
+		if err := os.WriteFile(file.path, file.content.Bytes(), 0644); err != nil {
+			fatalf("Failed to write %s: %v", file.path, err)
+		}
+		fmt.Printf("✅ Created %s\n", file.path)
 	}
+}
 
-	fmt.Printf("✅ Created %s\n", mdPath)
+// loadTemplates parses the embedded templates, and then the *.tmpl files of
+// dir if it exists, which replace the embedded templates of the same name.
+func loadTemplates(dir string) (*template.Template, error) {
+	tmpl := template.Must(template.New("event").Funcs(templateFuncs).ParseFS(tmplFS, "template/*.tmpl"))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return tmpl, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil || len(matches) == 0 {
+		return tmpl, err
+	}
+	for _, match := range matches {
+		fmt.Printf("Using template %s\n", match)
+	}
+	if _, err := tmpl.ParseFiles(matches...); err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	return tmpl, nil
 }
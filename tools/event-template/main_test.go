@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"catalog"
+)
+
+func TestTemplates(t *testing.T) {
+	tmpl, err := loadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// docs created with every flag have nothing left to do
+	meta := catalog.Metadata{
+		Description: "An order was created.",
+		Owner:       "team-orders",
+		Producers:   []string{"order-service"},
+		Consumers:   []string{"billing-service"},
+		Tags:        []string{"orders"},
+	}
+	for _, s := range []string{"order.created", "order.{order_id}.created", "order.{order_id}.item.{item_id}.added"} {
+		subject, err := catalog.ParseSubject(s, catalog.DefaultMaxDepth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"schema.json.tmpl", "schema.avsc.tmpl", "example.json.tmpl", "event.md.tmpl"} {
+			var b bytes.Buffer
+			if err := tmpl.ExecuteTemplate(&b, name, newEventTemplateData(subject, "1", "schema.json", meta)); err != nil {
+				t.Fatalf("%s for %s: %v", name, s, err)
+			}
+			out := b.String()
+			if name == "event.md.tmpl" {
+				if todos := todoLines(out); len(todos) > 0 {
+					t.Errorf("%s for %s has TODOs on lines %v:\n%s", name, s, todos, out)
+				}
+				out = out[strings.Index(out, "```json\n")+len("```json\n") : strings.LastIndex(out, "```")]
+			}
+			if !json.Valid([]byte(out)) {
+				t.Errorf("%s for %s is not valid JSON:\n%s", name, s, out)
+			}
+		}
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "event.md.tmpl"), []byte("# {{.Subject}} by {{pascal .Subject}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := loadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := struct{ Subject string }{"order.created"}
+	var b bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&b, "event.md.tmpl", data); err != nil {
+		t.Fatal(err)
+	}
+	if want := "# order.created by OrderCreated\n"; b.String() != want {
+		t.Errorf("got overridden template %q, want %q", b.String(), want)
+	}
+	// the templates the directory does not override fall back to the embedded ones
+	for _, name := range []string{"schema.json.tmpl", "schema.proto.tmpl", "schema.avsc.tmpl", "example.json.tmpl"} {
+		if tmpl.Lookup(name) == nil {
+			t.Errorf("no embedded %s", name)
+		}
+	}
+
+	// without the directory, all templates are embedded
+	tmpl, err = loadTemplates(filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Lookup("event.md.tmpl") == nil {
+		t.Error("no embedded event.md.tmpl")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "schema.json.tmpl"), []byte("{{.Subject"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTemplates(dir); err == nil || !strings.Contains(err.Error(), "schema.json.tmpl") {
+		t.Errorf("got error %v, want a parse error of schema.json.tmpl", err)
+	}
+}
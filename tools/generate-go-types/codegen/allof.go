@@ -0,0 +1,235 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// isObjectLike reports whether a schema describes an object, even if it
+// leaves "type" out.
+func isObjectLike(schema *Schema) bool {
+	return schemaType(schema) == TypeObject || len(schema.Properties) > 0 || len(schema.AllOf) > 0
+}
+
+// isAllOfObject reports whether schema composes objects with allOf. Branches
+// without properties, such as {"required": [...]}, only add constraints.
+func (g *Generator) isAllOfObject(schema *Schema, doc *document) bool {
+	if len(schema.AllOf) == 0 {
+		return false
+	}
+	objects := len(schema.Properties) > 0
+	for i := range schema.AllOf {
+		branch := &schema.AllOf[i]
+		if branch.Ref != "" {
+			branch, _, _, _ = g.resolveRef(branch.Ref, doc)
+		}
+		if len(branch.Type) > 0 && schemaType(branch) != TypeObject {
+			return false
+		}
+		objects = objects || isObjectLike(branch)
+	}
+	return objects && (len(schema.Type) == 0 || schemaType(schema) == TypeObject)
+}
+
+// typeSignature describes the Go type a schema maps to, to find allOf
+// branches that disagree about a property.
+func (g *Generator) typeSignature(schema *Schema, doc *document) string {
+	if schema.Ref != "" {
+		_, _, key, _ := g.resolveRef(schema.Ref, doc)
+		return "$ref " + key
+	}
+	if values, t, ok := enumValues(schema); ok {
+		b, _ := json.Marshal(values)
+		return string(t) + " enum " + string(b)
+	}
+	switch t := schemaType(schema); t {
+	case TypeArray:
+		items := "any"
+		if schema.Items != nil {
+			items = g.typeSignature(schema.Items, doc)
+		}
+		return "array of " + items
+	case TypeObject:
+		var props []string
+		for propName, prop := range schema.Properties {
+			props = append(props, propName+": "+g.typeSignature(&prop, doc))
+		}
+		sort.Strings(props)
+		return "object {" + strings.Join(props, ", ") + "}"
+	case TypeString:
+		if schema.Format != "" {
+			return "string (" + schema.Format + ")"
+		}
+		return string(t)
+	case "":
+		return "any"
+	default:
+		return string(t)
+	}
+}
+
+// allOfMerge accumulates the branches of an allOf.
+type allOfMerge struct {
+	g        *Generator
+	name     string
+	doc      *document
+	props    map[string]Schema
+	required map[string]bool
+	// allRequired holds the properties any branch requires, directly or
+	// through a $ref.
+	allRequired map[string]bool
+	embeds      []jen.Code
+	// seen records where each property was first defined and its type
+	// signature.
+	seen map[string]allOfProp
+}
+
+type allOfProp struct {
+	origin, signature string
+}
+
+// add records a property defined at origin, reporting an error if another
+// branch gave it a different type. It returns false if the property is
+// already provided with the same type.
+func (m *allOfMerge) add(propName string, prop *Schema, origin string) bool {
+	sig := m.g.typeSignature(prop, m.doc)
+	prev, ok := m.seen[propName]
+	if !ok {
+		m.seen[propName] = allOfProp{origin: origin, signature: sig}
+		return true
+	}
+	if prev.signature != sig {
+		m.g.fail(ErrAllOfConflict, "property %q of %s is %s in %s but %s in %s", propName, m.name, prev.signature, prev.origin, sig, origin)
+	}
+	return false
+}
+
+// mergeProps adds properties as fields of the outer struct.
+func (m *allOfMerge) mergeProps(props map[string]Schema, required map[string]bool, origin string) {
+	var propNames []string
+	for propName := range props {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+	for _, propName := range propNames {
+		prop := props[propName]
+		if m.add(propName, &prop, origin) {
+			m.props[propName] = prop
+		}
+	}
+	for propName := range required {
+		m.required[propName] = true
+	}
+}
+
+// merge adds the properties and required list of an inline schema and
+// recurses into its allOf.
+func (m *allOfMerge) merge(schema *Schema, origin string) {
+	required := make(map[string]bool)
+	for _, propName := range schema.Required {
+		required[propName] = true
+	}
+	m.mergeProps(schema.Properties, required, origin)
+
+	for i := range schema.AllOf {
+		branch := &schema.AllOf[i]
+		branchOrigin := fmt.Sprintf("%s/allOf/%d", origin, i)
+		if branch.Ref == "" {
+			m.merge(branch, branchOrigin)
+			continue
+		}
+
+		target, targetDoc, key, name := m.g.resolveRef(branch.Ref, m.doc)
+		props, required := m.g.collectProps(target, targetDoc)
+		branchOrigin += " ($ref " + branch.Ref + ")"
+
+		// encoding/json ignores fields that two embedded structs both
+		// provide, so a $ref sharing properties with another branch is
+		// merged into the outer struct instead of being embedded, as is
+		// one with a property another branch makes required
+		merge := false
+		for propName, prop := range props {
+			if _, ok := m.seen[propName]; ok {
+				merge = true
+				m.add(propName, &prop, branchOrigin)
+			}
+			merge = merge || m.allRequired[propName] && !required[propName]
+		}
+		if merge {
+			m.mergeProps(props, required, branchOrigin)
+			continue
+		}
+		m.embeds = append(m.embeds, m.g.generateRefType(key, name, target, targetDoc))
+		for propName, prop := range props {
+			m.add(propName, &prop, branchOrigin)
+		}
+	}
+}
+
+// collectProps returns every property a schema defines, directly or through
+// allOf, and which of them are required.
+func (g *Generator) collectProps(schema *Schema, doc *document) (map[string]Schema, map[string]bool) {
+	props := make(map[string]Schema)
+	required := make(map[string]bool)
+	for propName, prop := range schema.Properties {
+		props[propName] = prop
+	}
+	for _, propName := range schema.Required {
+		required[propName] = true
+	}
+	for i := range schema.AllOf {
+		branch, branchDoc := &schema.AllOf[i], doc
+		if branch.Ref != "" {
+			branch, branchDoc, _, _ = g.resolveRef(branch.Ref, doc)
+		}
+		branchProps, branchRequired := g.collectProps(branch, branchDoc)
+		for propName, prop := range branchProps {
+			if _, ok := props[propName]; !ok {
+				props[propName] = prop
+			}
+		}
+		for propName := range branchRequired {
+			required[propName] = true
+		}
+	}
+	return props, required
+}
+
+// generateAllOf emits a single struct for an allOf: branches that are a
+// $ref become embedded structs, and the properties of the other branches
+// are merged into it with their required lists unioned. A $ref whose
+// properties the other branches redefine or require is merged too.
+func (g *Generator) generateAllOf(name, scope string, schema *Schema, doc *document, reuse bool) string {
+	_, allRequired := g.collectProps(schema, doc)
+	m := &allOfMerge{
+		g:           g,
+		name:        name,
+		doc:         doc,
+		props:       make(map[string]Schema),
+		required:    make(map[string]bool),
+		allRequired: allRequired,
+		seen:        make(map[string]allOfProp),
+	}
+	m.merge(schema, "#")
+
+	merged := Schema{Properties: m.props}
+	for propName := range m.required {
+		merged.Required = append(merged.Required, propName)
+	}
+	fields, defaults := g.structFields(name, scope, &merged, doc)
+	embedded := &structDefaults{init: defaults.init}
+	for _, embed := range m.embeds {
+		g.addDefaultEmbed(embedded, embed)
+	}
+	defaults.apply = append(embedded.apply, defaults.apply...)
+	def := jen.Struct(append(m.embeds, fields...)...)
+	return g.declareType(name, g.goSource(def)+g.defaultsShape(defaults), doc.out, reuse, func() {
+		typeComment(doc.out, schema)
+		doc.out.file.Type().Id(name).Add(def).Line()
+		g.generateDefaults(name, defaults, doc.out)
+	})
+}
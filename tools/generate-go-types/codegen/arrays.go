@@ -0,0 +1,208 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// tupleBounds returns the number of items a tuple schema allows: at least
+// min, and at most max if max is not negative.
+func tupleBounds(schema *Schema) (min, max int) {
+	max = -1
+	if schema.Items != nil && schema.Items.IsFalse() {
+		max = len(schema.PrefixItems)
+	}
+	if schema.MaxItems > 0 && (max < 0 || schema.MaxItems < max) {
+		max = schema.MaxItems
+	}
+	return schema.MinItems, max
+}
+
+// tupleFieldName names the field for the prefix item i of a tuple after its
+// title, or else its position.
+func (g *Generator) tupleFieldName(i int, item *Schema) string {
+	if item.Title != "" {
+		return g.formatId(item.Title)
+	}
+	return "Item" + strconv.Itoa(i)
+}
+
+// generateTuple emits a struct for an array with "prefixItems": one field
+// per prefix item, and a Rest slice for the items after them unless
+// "items" is false. It is encoded as a JSON array, and decoding it checks
+// minItems and maxItems.
+func (g *Generator) generateTuple(name string, schema *Schema, doc *document, reuse bool) string {
+	var fields []jen.Code
+	var targets, values []jen.Code
+	for i := range schema.PrefixItems {
+		restore := g.at("prefixItems", strconv.Itoa(i))
+		item := &schema.PrefixItems[i]
+		id := g.tupleFieldName(i, item)
+		t := g.generateSchemaType(name, "item"+strconv.Itoa(i), item, doc, true)
+		// the title already names the field
+		annotations := *item
+		annotations.Title = ""
+		fields = append(fields, comment(docLines(&annotations, "item")).Id(id).Add(t))
+		targets = append(targets, jen.Op("&").Id("v").Dot(id))
+		values = append(values, jen.Id("v").Dot(id))
+		restore()
+	}
+	min, max := tupleBounds(schema)
+	rest := max < 0 || max > len(schema.PrefixItems)
+	var restType jen.Code
+	if rest {
+		restore := g.at("items")
+		restType = g.generateSchemaType(name, "rest", schema.Items, doc, true)
+		fields = append(fields, jen.Comment("Rest holds the items after the prefix items.").Line().Id("Rest").Index().Add(restType))
+		restore()
+	}
+	def := jen.Struct(fields...)
+
+	shape := fmt.Sprintf("tuple %d %d %s", min, max, g.goSource(def))
+	return g.declareType(name, shape, doc.out, reuse, func() {
+		f := doc.out.file
+		typeComment(doc.out, schema)
+		if len(docLines(schema, "type")) > 0 {
+			f.Comment("//")
+		}
+		f.Commentf("%s is a tuple, encoded as a JSON array.", name)
+		f.Type().Id(name).Add(def).Line()
+
+		marshal := []jen.Code{jen.Id("items").Op(":=").Index().Any().Values(values...)}
+		if rest {
+			marshal = append(marshal, jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Id("v").Dot("Rest")).Block(
+				jen.Id("items").Op("=").Append(jen.Id("items"), jen.Id("item")),
+			))
+		}
+		marshal = append(marshal, jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("items"))))
+		f.Func().Params(jen.Id("v").Id(name)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(marshal...).Line()
+
+		unmarshal := []jen.Code{
+			jen.Var().Id("items").Index().Qual("encoding/json", "RawMessage"),
+			jen.If(jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("items")), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+		}
+		if min > 0 {
+			unmarshal = append(unmarshal, jen.If(jen.Len(jen.Id("items")).Op("<").Lit(min)).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(name+" has %d items, want at least "+strconv.Itoa(min)), jen.Len(jen.Id("items")))),
+			))
+		}
+		if max >= 0 {
+			unmarshal = append(unmarshal, jen.If(jen.Len(jen.Id("items")).Op(">").Lit(max)).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(name+" has %d items, want at most "+strconv.Itoa(max)), jen.Len(jen.Id("items")))),
+			))
+		}
+		decode := func(target jen.Code) jen.Code {
+			return jen.If(jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("item"), target), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			)
+		}
+		loop := []jen.Code{decode(jen.Id("targets").Index(jen.Id("i")))}
+		if rest {
+			loop = []jen.Code{
+				jen.If(jen.Id("i").Op("<").Len(jen.Id("targets"))).Block(
+					decode(jen.Id("targets").Index(jen.Id("i"))),
+					jen.Continue(),
+				),
+				jen.Var().Id("r").Add(restType),
+				decode(jen.Op("&").Id("r")),
+				jen.Id("v").Dot("Rest").Op("=").Append(jen.Id("v").Dot("Rest"), jen.Id("r")),
+			}
+		}
+		unmarshal = append(unmarshal,
+			jen.Op("*").Id("v").Op("=").Id(name).Values(),
+			jen.Id("targets").Op(":=").Index().Any().Values(targets...),
+			jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("items")).Block(loop...),
+			jen.Return(jen.Nil()),
+		)
+		f.Func().Params(jen.Id("v").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(unmarshal...).Line()
+	})
+}
+
+// orderedItems reports whether the items of an array are strings, integers
+// or numbers, which can be the keys of a Set.
+func (g *Generator) orderedItems(items *Schema, doc *document) bool {
+	if items == nil {
+		return false
+	}
+	if items.Ref != "" {
+		items, doc, _, _ = g.resolveRef(items.Ref, doc)
+	}
+	if items.GoType != "" {
+		return false
+	}
+	if _, _, ok := enumValues(items); ok {
+		return true
+	}
+	if _, ok := nullableSchema(items); ok || len(items.Type) > 1 {
+		return false
+	}
+	switch schemaType(items) {
+	case TypeString:
+		if items.Format == "" {
+			return true
+		}
+		t, _ := g.formatType(items.Format, doc.out)
+		return t == nil
+	case TypeInteger, TypeNumber:
+		return true
+	}
+	return false
+}
+
+// generateSetType emits the generic Set type.
+func (g *Generator) generateSetType(out *output) string {
+	const name = "Set"
+	return g.declareType(name, "set", out, false, func() {
+		f := out.file
+		recv := func() *jen.Statement { return jen.Id("s").Id(name).Types(jen.Id("T")) }
+		f.Comment("Set is an array of unique items. It is encoded in sorted order, and")
+		f.Comment("decoding it rejects duplicate items.")
+		f.Type().Id(name).Types(jen.Id("T").Qual("cmp", "Ordered")).Map(jen.Id("T")).Struct().Line()
+
+		f.Comment("Add adds values to the set, which must not be nil.")
+		f.Func().Params(recv()).Id("Add").Params(jen.Id("values").Op("...").Id("T")).Block(
+			jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("values")).Block(
+				jen.Id("s").Index(jen.Id("v")).Op("=").Struct().Values(),
+			),
+		).Line()
+
+		f.Comment("Has reports whether value is in the set.")
+		f.Func().Params(recv()).Id("Has").Params(jen.Id("value").Id("T")).Bool().Block(
+			jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Id("s").Index(jen.Id("value")),
+			jen.Return(jen.Id("ok")),
+		).Line()
+
+		f.Comment("Values returns the items of the set in sorted order.")
+		f.Func().Params(recv()).Id("Values").Params().Index().Id("T").Block(
+			jen.Id("values").Op(":=").Make(jen.Index().Id("T"), jen.Lit(0), jen.Len(jen.Id("s"))),
+			jen.For(jen.Id("v").Op(":=").Range().Id("s")).Block(
+				jen.Id("values").Op("=").Append(jen.Id("values"), jen.Id("v")),
+			),
+			jen.Qual("slices", "Sort").Call(jen.Id("values")),
+			jen.Return(jen.Id("values")),
+		).Line()
+
+		f.Func().Params(recv()).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+			jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("s").Dot("Values").Call())),
+		).Line()
+
+		f.Func().Params(jen.Id("s").Op("*").Id(name).Types(jen.Id("T"))).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+			jen.Var().Id("values").Index().Id("T"),
+			jen.If(jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("values")), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.Op("*").Id("s").Op("=").Make(jen.Id(name).Types(jen.Id("T")), jen.Len(jen.Id("values"))),
+			jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("values")).Block(
+				jen.If(jen.Id("s").Dot("Has").Call(jen.Id("v"))).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("duplicate item %v"), jen.Id("v"))),
+				),
+				jen.Id("s").Dot("Add").Call(jen.Id("v")),
+			),
+			jen.Return(jen.Nil()),
+		).Line()
+	})
+}
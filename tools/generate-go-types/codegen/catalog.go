@@ -0,0 +1,223 @@
+package codegen
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// eventSchemaPattern matches the schema files of a catalog, e.g.
+// "v1.schema.json".
+var eventSchemaPattern = regexp.MustCompile(`^(v[0-9]+)\.schema\.json$`)
+
+// catalogEvent is one version of an event schema in a catalog.
+type catalogEvent struct {
+	// subject is the dotted NATS subject, e.g. "order.{order_id}.created".
+	subject string
+	version string
+	// filename is the schema file.
+	filename string
+	// pkgDir is the package directory relative to the output directory,
+	// e.g. "order/created/v1".
+	pkgDir string
+	doc    *document
+	// root is the name of the payload type, empty if the schema's root is
+	// a $ref.
+	root string
+}
+
+// findEvents walks a catalog directory for event schemas.
+func findEvents(eventsDir string) ([]*catalogEvent, error) {
+	var events []*catalogEvent
+	err := filepath.WalkDir(eventsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		m := eventSchemaPattern.FindStringSubmatch(d.Name())
+		if d.IsDir() || m == nil {
+			return nil
+		}
+		rel, err := filepath.Rel(eventsDir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		tokens := strings.Split(filepath.ToSlash(rel), "/")
+
+		// parameters such as {order_id} are not part of the package path
+		var pkgTokens []string
+		for _, t := range tokens {
+			if !strings.HasPrefix(t, "{") {
+				pkgTokens = append(pkgTokens, t)
+			}
+		}
+		events = append(events, &catalogEvent{
+			subject:  strings.Join(tokens, "."),
+			version:  m[1],
+			filename: p,
+			pkgDir:   path.Join(append(pkgTokens, m[1])...),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk catalog directory: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no event schemas found in %s", eventsDir)
+	}
+	return events, nil
+}
+
+// rootTypeName names the payload type of an event after the schema's
+// title, or else after the subject, e.g. "OrderCreated".
+func (g *Generator) rootTypeName(schema *Schema, pkgDir string) string {
+	if schema.Title != "" {
+		return g.formatId(schema.Title)
+	}
+	return g.formatId(strings.Join(strings.Split(path.Dir(pkgDir), "/"), "_"))
+}
+
+// importAlias returns the import name of an event package in the registry,
+// e.g. "ordercreatedv1".
+func importAlias(pkgDir string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(pkgDir))
+}
+
+// Catalog generates one package per event schema under eventsDir into a
+// directory whose import path is modulePath, along with a common package for
+// the other schema files they reference and a registry of them all. The
+// common package is modulePath + "/common" unless Options.CommonPath sets
+// another package under modulePath.
+//
+// It returns the source of every file keyed by its slash-separated path
+// relative to the output directory, e.g. "order/created/v1/types.go". For
+// TypeScript, modulePath is unused and each event is a module such as
+// "order/created/v1.ts", with the other schema files in "common.ts".
+func (g *Generator) Catalog(eventsDir, modulePath string) (files map[string][]byte, err error) {
+	events, err := findEvents(eventsDir)
+	if err != nil {
+		return nil, err
+	}
+	if g.opts.Lang == LangTypeScript {
+		defer g.catch(&err)
+		return g.tsCatalog(events), nil
+	}
+	if g.commonOutput == nil {
+		commonPath := modulePath + "/common"
+		g.commonOutput = newOutput(jen.NewFilePathName(commonPath, "common"), commonPath)
+		// schemas passed to Load go in the common package
+		for _, d := range g.documents {
+			d.out = g.commonOutput
+		}
+	}
+	commonDir := strings.TrimPrefix(g.commonOutput.path, modulePath+"/")
+	if commonDir == g.commonOutput.path {
+		return nil, fmt.Errorf("common package %s is not under %s", g.commonOutput.path, modulePath)
+	}
+	defer g.catch(&err)
+
+	// every event is loaded before any is generated, so that a $ref from
+	// one event to another resolves to the other event's package rather
+	// than the common one
+	subjects := make(map[string]string)
+	for _, e := range events {
+		if other, ok := subjects[e.pkgDir]; ok && other != e.subject {
+			g.failAt(e.filename, ErrNameCollision, "subjects %s and %s both map to package %s", other, e.subject, e.pkgDir)
+		}
+		subjects[e.pkgDir] = e.subject
+		importPath := modulePath + "/" + e.pkgDir
+		out := newOutput(jen.NewFilePathName(importPath, e.version), importPath)
+		e.doc = g.loadDocument(e.filename, out)
+	}
+	// events referencing each other import them under distinct names
+	// rather than as a row of "v1"s
+	for _, e := range events {
+		for _, other := range events {
+			if other != e {
+				e.doc.out.file.ImportAlias(other.doc.out.path, importAlias(other.pkgDir))
+			}
+		}
+	}
+
+	for _, e := range events {
+		if e.doc.schema.Ref == "" {
+			e.root = g.rootTypeName(e.doc.schema, e.pkgDir)
+		}
+		g.generateDocument(e.doc, e.root)
+	}
+	g.generateCommonDefs()
+
+	files = make(map[string][]byte)
+	add := func(name string, out *output) {
+		src, err := render(out)
+		if err != nil {
+			g.failAt(name, ErrLoad, "%v", err)
+		}
+		files[name] = src
+	}
+	for _, e := range events {
+		add(e.pkgDir+"/types.go", e.doc.out)
+	}
+	if len(g.commonOutput.types) > 0 {
+		add(commonDir+"/types.go", g.commonOutput)
+	}
+	add("registry.go", generateRegistry(events, path.Base(modulePath)))
+	return files, nil
+}
+
+// generateRegistry emits a Constructors map from subject and version to a
+// function returning a new payload of that event, and a New helper.
+func generateRegistry(events []*catalogEvent, pkgName string) *output {
+	out := newOutput(jen.NewFile(pkgName), "")
+	f := out.file
+	for _, e := range events {
+		f.ImportAlias(e.doc.out.path, importAlias(e.pkgDir))
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].subject != events[j].subject {
+			return events[i].subject < events[j].subject
+		}
+		return events[i].version < events[j].version
+	})
+
+	subjects := jen.Dict{}
+	versions := jen.Dict{}
+	for i, e := range events {
+		if e.root != "" {
+			versions[jen.Lit(e.version)] = jen.Func().Params().Any().Block(
+				jen.Return(jen.Op("&").Add(e.doc.out.typeRef(e.root)).Values()),
+			)
+		}
+		if i == len(events)-1 || events[i+1].subject != e.subject {
+			if len(versions) > 0 {
+				subjects[jen.Lit(e.subject)] = jen.Values(versions)
+			}
+			versions = jen.Dict{}
+		}
+	}
+
+	constructor := jen.Func().Params().Any()
+	f.Comment("Constructors maps an event subject and schema version to a function")
+	f.Comment("returning a new, empty payload of that event.")
+	f.Var().Id("Constructors").Op("=").Map(jen.String()).Map(jen.String()).Add(constructor).Values(subjects).Line()
+
+	f.Comment("New returns a new, empty payload for the given subject and version, such")
+	f.Comment(`as "order.{order_id}.created" and "v1", ready to be unmarshalled into.`)
+	f.Func().Id("New").Params(jen.List(jen.Id("subject"), jen.Id("version")).String()).Params(jen.Any(), jen.Bool()).Block(
+		jen.List(jen.Id("c"), jen.Id("ok")).Op(":=").Id("Constructors").Index(jen.Id("subject")).Index(jen.Id("version")),
+		jen.If(jen.Op("!").Id("ok")).Block(jen.Return(jen.Nil(), jen.False())),
+		jen.Return(jen.Id("c").Call(), jen.True()),
+	)
+	return out
+}
@@ -0,0 +1,299 @@
+// Package codegen generates Go types and helpers from JSON schemas.
+//
+// A Generator loads one or more schema files, generates a type for the root
+// of a schema and each of its definitions, and renders them as Go source:
+//
+//	g := codegen.New(codegen.Options{PackageName: "types"})
+//	if err := g.Generate("order.schema.json", "Order"); err != nil {
+//		return err
+//	}
+//	src, err := g.Source()
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// Languages a Generator can emit.
+const (
+	LangGo         = "go"
+	LangTypeScript = "ts"
+)
+
+// Options configure a Generator.
+type Options struct {
+	// Lang is the language to generate, LangGo if empty. For TypeScript,
+	// PackageName, CommonPath, NoFormats, Nullable, Sets and all of Config
+	// but its initialisms are ignored, and types from other schema files are
+	// generated into the main output.
+	Lang string
+	// PackageName is the name of the package of the main output.
+	PackageName string
+	// CommonPath is the import path of the package types from other schema
+	// files are generated into. If empty, they are generated into the main
+	// output.
+	CommonPath string
+	// Config overrides the Go types of formats and struct fields.
+	Config Config
+	// NoFormats generates strings with a "format" as plain strings rather
+	// than time.Time, Date, UUID, URL and Duration.
+	NoFormats bool
+	// Nullable generates optional properties which may be null as
+	// Nullable[T] rather than *T, to tell an absent property from null.
+	Nullable bool
+	// Sets generates arrays of unique strings, integers or numbers as
+	// Set[T] rather than []T.
+	Sets bool
+}
+
+// Generator generates Go code from JSON schemas. It is not safe for
+// concurrent use.
+type Generator struct {
+	opts Options
+
+	// documents holds every loaded schema, keyed by both its file URL and
+	// its $id.
+	documents map[string]*document
+	// refTypes maps a resolved $ref, in "<base>#<fragment>" form, to the
+	// type generated for it, and so does it for the location of every named
+	// nested type.
+	refTypes map[string]*jen.Statement
+	// pending holds the keys of the types being generated, to break
+	// recursive references with a pointer.
+	pending map[string]bool
+	// initialisms maps the upper case form of every configured initialism
+	// to how it is written.
+	initialisms map[string]string
+	// defaulted maps the Go source of every struct type with defaults to
+	// its NewX constructor.
+	defaulted map[string]*jen.Statement
+
+	mainOutput, commonOutput *output
+
+	// tsRefs, tsOutputs and tsShared are the TypeScript counterparts of
+	// refTypes and the outputs: documents without a module of their own
+	// are generated into tsShared.
+	tsRefs    map[string]tsRef
+	tsOutputs map[*document]*tsOutput
+	tsShared  *tsOutput
+
+	// doc and pointer locate the schema being generated, for errors.
+	doc     *document
+	pointer string
+}
+
+// New returns a Generator with the given options.
+func New(opts Options) *Generator {
+	g := &Generator{
+		opts:       opts,
+		documents:  make(map[string]*document),
+		refTypes:   make(map[string]*jen.Statement),
+		pending:    make(map[string]bool),
+		defaulted:  make(map[string]*jen.Statement),
+		mainOutput: newOutput(jen.NewFile(opts.PackageName), ""),
+		tsRefs:     make(map[string]tsRef),
+		tsOutputs:  make(map[*document]*tsOutput),
+		tsShared:   newTSOutput(""),
+	}
+	if opts.Lang == LangTypeScript {
+		opts.CommonPath = ""
+	}
+	g.initialisms = make(map[string]string)
+	for _, initialism := range opts.Config.Initialisms {
+		g.initialisms[strings.ToUpper(initialism)] = initialism
+	}
+	if opts.CommonPath != "" {
+		commonName := opts.CommonPath[strings.LastIndex(opts.CommonPath, "/")+1:]
+		g.commonOutput = newOutput(jen.NewFilePathName(opts.CommonPath, commonName), opts.CommonPath)
+	}
+	return g
+}
+
+// Load loads an additional schema file, so that $refs can resolve it by its
+// $id. Its definitions are generated into the common output, if any.
+func (g *Generator) Load(filename string) (err error) {
+	defer g.catch(&err)
+	g.loadDocument(filename, g.sharedOutput())
+	return nil
+}
+
+// Generate loads a schema file and generates its root type, named rootName,
+// and all of its definitions into the main output.
+func (g *Generator) Generate(filename, rootName string) (err error) {
+	defer g.catch(&err)
+	doc := g.loadDocument(filename, g.mainOutput)
+	if g.opts.Lang == LangTypeScript {
+		g.tsDocument(doc, rootName)
+	} else {
+		g.generateDocument(doc, rootName)
+	}
+	return nil
+}
+
+// Source renders the main output.
+func (g *Generator) Source() ([]byte, error) {
+	if g.opts.Lang == LangTypeScript {
+		return tsSource(g.tsShared), nil
+	}
+	return render(g.mainOutput)
+}
+
+// CommonSource generates every definition of the schema files loaded into
+// the common output, so the common package doesn't depend on which of them
+// a schema happens to use, and renders it.
+func (g *Generator) CommonSource() (src []byte, err error) {
+	if g.commonOutput == nil || g.opts.Lang == LangTypeScript {
+		return nil, errors.New("no common package configured")
+	}
+	defer g.catch(&err)
+	g.generateCommonDefs()
+	return render(g.commonOutput)
+}
+
+func render(out *output) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := out.file.Render(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sharedOutput returns the output for types from schema files other than
+// the one being generated.
+func (g *Generator) sharedOutput() *output {
+	if g.commonOutput != nil {
+		return g.commonOutput
+	}
+	return g.mainOutput
+}
+
+// generateDocument generates the root type of a schema document, named
+// rootName, and all of its definitions.
+func (g *Generator) generateDocument(doc *document, rootName string) {
+	defer g.in(doc, "")()
+	if doc.schema.Ref == "" {
+		key := doc.base.String() + "#"
+		g.refTypes[key] = doc.out.typeRef(rootName)
+		g.pending[key] = true
+		g.generateDef(doc.schema, doc, rootName)
+		delete(g.pending, key)
+	}
+	g.generateDefs(doc)
+}
+
+// generateCommonDefs generates every definition of the documents loaded
+// into the common output.
+func (g *Generator) generateCommonDefs() {
+	done := make(map[*document]bool)
+	for {
+		var pending []*document
+		for _, d := range g.documents {
+			if d.out == g.commonOutput && !done[d] {
+				pending = append(pending, d)
+				done[d] = true
+			}
+		}
+		if len(pending) == 0 {
+			return
+		}
+		sortDocuments(pending)
+		for _, d := range pending {
+			g.generateDefs(d)
+		}
+	}
+}
+
+var (
+	// ErrLoad is returned for schema files which can't be read or decoded.
+	ErrLoad = errors.New("failed to load schema")
+	// ErrInvalidRef is returned for a $ref which can't be resolved.
+	ErrInvalidRef = errors.New("invalid $ref")
+	// ErrNameCollision is returned when two different schemas, enum values
+	// or properties map to the same Go name.
+	ErrNameCollision = errors.New("name collision")
+	// ErrAllOfConflict is returned when the branches of an allOf give a
+	// property different types.
+	ErrAllOfConflict = errors.New("conflicting allOf branches")
+	// ErrInvalidCode is returned when the generated code doesn't format,
+	// e.g. for a configured Go type which doesn't parse.
+	ErrInvalidCode = errors.New("invalid Go code")
+	// ErrInvalidName is returned for an "x-go-name" which is not a Go
+	// identifier.
+	ErrInvalidName = errors.New("invalid Go name")
+)
+
+// Error is an error in a schema. It wraps one of the Err* values.
+type Error struct {
+	// Path locates the offending schema as its file name followed by a
+	// JSON pointer, e.g. "order.schema.json#/properties/items".
+	Path string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// fail aborts generation with an error at the current location. The panic
+// is recovered by catch in the exported methods.
+func (g *Generator) fail(kind error, format string, args ...interface{}) {
+	g.failAt(g.location(), kind, format, args...)
+}
+
+// failAt is like fail for an error at the given path.
+func (g *Generator) failAt(path string, kind error, format string, args ...interface{}) {
+	panic(&Error{
+		Path: path,
+		Err:  fmt.Errorf("%w: %s", kind, fmt.Sprintf(format, args...)),
+	})
+}
+
+// catch recovers an error raised by fail into *err. Other panics are bugs,
+// as code which may not render is checked by goSource, and are re-raised.
+func (g *Generator) catch(err *error) {
+	if r := recover(); r != nil {
+		e, ok := r.(*Error)
+		if !ok {
+			panic(r)
+		}
+		*err = e
+	}
+}
+
+func (g *Generator) location() string {
+	if g.doc == nil {
+		return "#" + g.pointer
+	}
+	return g.doc.filename + "#" + g.pointer
+}
+
+// at descends into the schema under the given keywords and property names,
+// returning a function going back up.
+func (g *Generator) at(tokens ...string) func() {
+	pointer := g.pointer
+	for _, token := range tokens {
+		g.pointer += "/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	}
+	return func() {
+		g.pointer = pointer
+	}
+}
+
+// in moves to the schema at a JSON pointer of doc, returning a function
+// going back.
+func (g *Generator) in(doc *document, pointer string) func() {
+	prevDoc, prevPointer := g.doc, g.pointer
+	g.doc, g.pointer = doc, pointer
+	return func() {
+		g.doc, g.pointer = prevDoc, prevPointer
+	}
+}
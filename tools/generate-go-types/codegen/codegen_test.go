@@ -0,0 +1,291 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// archive concatenates generated files, each preceded by a "-- name --"
+// line, in name order.
+func archive(files map[string][]byte) []byte {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		buf.WriteString("-- " + name + " --\n")
+		buf.Write(files[name])
+	}
+	return buf.Bytes()
+}
+
+// checkGolden compares got with testdata/<name>.golden, rewriting it with
+// -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from %s, run go test -update to accept it:\n%s", golden, got)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		config string
+		load   []string
+	}{
+		{name: "types"},
+		{name: "enum"},
+		{name: "formats"},
+		{name: "formats_disabled", opts: Options{NoFormats: true}},
+		{name: "refs", load: []string{"shared.schema.json"}},
+		{name: "refs_common", opts: Options{CommonPath: "example.com/gen/common"}, load: []string{"shared.schema.json"}},
+		{name: "union"},
+		{name: "allof"},
+		{name: "maps"},
+		{name: "comments"},
+		{name: "extensions", config: "extensions.config.json"},
+		{name: "ignored"},
+		{name: "naming", config: "naming.config.json"},
+		{name: "identifiers"},
+		{name: "nullable"},
+		{name: "nullable_wrapper", opts: Options{Nullable: true}},
+		{name: "defaults"},
+		{name: "arrays"},
+		{name: "arrays_sets", opts: Options{Sets: true}},
+		{name: "types_ts", opts: Options{Lang: LangTypeScript}},
+		{name: "enum_ts", opts: Options{Lang: LangTypeScript}},
+		{name: "refs_ts", opts: Options{Lang: LangTypeScript}, load: []string{"shared.schema.json"}},
+		{name: "union_ts", opts: Options{Lang: LangTypeScript}},
+		{name: "allof_ts", opts: Options{Lang: LangTypeScript}},
+		{name: "maps_ts", opts: Options{Lang: LangTypeScript}},
+		{name: "comments_ts", opts: Options{Lang: LangTypeScript}},
+		{name: "nullable_ts", opts: Options{Lang: LangTypeScript}},
+		{name: "arrays_ts", opts: Options{Lang: LangTypeScript}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.PackageName = "golden"
+			if tt.config != "" {
+				config, err := LoadConfig(filepath.Join("testdata", tt.config))
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts.Config = config
+			}
+
+			// variants of a case share its schema
+			schema, _, _ := strings.Cut(tt.name, "_")
+			g := New(opts)
+			for _, filename := range tt.load {
+				if err := g.Load(filepath.Join("testdata", filename)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := g.Generate(filepath.Join("testdata", schema+".schema.json"), "Root"); err != nil {
+				t.Fatal(err)
+			}
+			files := make(map[string][]byte)
+			src, err := g.Source()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.Lang == LangTypeScript {
+				files["types.ts"] = src
+			} else {
+				files["types.go"] = src
+			}
+			if opts.CommonPath != "" {
+				src, err := g.CommonSource()
+				if err != nil {
+					t.Fatal(err)
+				}
+				files["common/types.go"] = src
+			}
+			checkGolden(t, tt.name, archive(files))
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	g := New(Options{})
+	if err := g.Load("testdata/catalog/common/metadata.schema.json"); err != nil {
+		t.Fatal(err)
+	}
+	files, err := g.Catalog("testdata/catalog/events", "example.com/gen/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "catalog", archive(files))
+}
+
+func TestCatalogTypeScript(t *testing.T) {
+	g := New(Options{Lang: LangTypeScript})
+	if err := g.Load("testdata/catalog/common/metadata.schema.json"); err != nil {
+		t.Fatal(err)
+	}
+	files, err := g.Catalog("testdata/catalog/events", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "catalog_ts", archive(files))
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{name: "valid", config: `{"tags": {"yaml": {"naming": "snake"}}, "initialisms": ["ID"]}`},
+		{name: "json tags", config: `{"tags": {"json": {"naming": "camel"}}}`, err: "json tags"},
+		{name: "unknown naming", config: `{"tags": {"db": {"naming": "shouty"}}}`, err: "unknown naming"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(filename, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(filename)
+			if tt.err == "" && err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		config Config
+		err    error
+		path   string
+	}{
+		{
+			name:   "invalid JSON",
+			schema: `{"type": "object",}`,
+			err:    ErrLoad,
+			path:   "",
+		},
+		{
+			name:   "missing definition",
+			schema: `{"type": "object", "properties": {"a": {"$ref": "#/$defs/Missing"}}}`,
+			err:    ErrInvalidRef,
+			path:   "#/properties/a",
+		},
+		{
+			name:   "missing anchor",
+			schema: `{"type": "object", "properties": {"a": {"type": "array", "items": {"$ref": "#missing"}}}}`,
+			err:    ErrInvalidRef,
+			path:   "#/properties/a/items",
+		},
+		{
+			name:   "missing file",
+			schema: `{"$defs": {"A": {"$ref": "missing.schema.json"}}}`,
+			err:    ErrInvalidRef,
+			path:   "#/$defs/A",
+		},
+		{
+			name: "type name collision",
+			schema: `{"type": "object", "properties": {
+				"a": {"title": "Item", "type": "object", "properties": {"x": {"type": "string"}}},
+				"b": {"title": "Item", "type": "object", "properties": {"y": {"type": "string"}}}
+			}}`,
+			err:  ErrNameCollision,
+			path: "#/properties/b",
+		},
+		{
+			name:   "enum value collision",
+			schema: `{"$defs": {"Sign": {"enum": ["a-b", "a_b"]}}}`,
+			err:    ErrNameCollision,
+			path:   "#/$defs/Sign",
+		},
+		{
+			name:   "extra field collision",
+			schema: `{"type": "object", "properties": {"extra": {"type": "string"}}, "additionalProperties": {"type": "string"}}`,
+			err:    ErrNameCollision,
+			path:   "#",
+		},
+		{
+			name:   "field name collision",
+			schema: `{"type": "object", "properties": {"a_b": {"type": "string"}, "aB": {"type": "string"}}}`,
+			err:    ErrNameCollision,
+			path:   "#/properties/a_b",
+		},
+		{
+			name:   "non-identifier name collision",
+			schema: `{"type": "object", "properties": {"1st": {"type": "string"}, "X1st": {"type": "string"}}}`,
+			err:    ErrNameCollision,
+			path:   "#/properties/X1st",
+		},
+		{
+			name:   "invalid x-go-name",
+			schema: `{"type": "object", "properties": {"a": {"type": "string", "x-go-name": "1st"}}}`,
+			err:    ErrInvalidName,
+			path:   "#/properties/a",
+		},
+		{
+			name:   "invalid configured type",
+			schema: `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+			config: Config{Properties: map[string]string{"Root.a": "map[string"}},
+			err:    ErrInvalidCode,
+			path:   "#/properties/a",
+		},
+		{
+			name: "allOf conflict",
+			schema: `{"type": "object", "properties": {"a": {"allOf": [
+				{"properties": {"x": {"type": "string"}}},
+				{"properties": {"x": {"type": "integer"}}}
+			]}}}`,
+			err:  ErrAllOfConflict,
+			path: "#/properties/a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "test.schema.json")
+			if err := os.WriteFile(filename, []byte(tt.schema), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := New(Options{PackageName: "test", Config: tt.config}).Generate(filename, "Root")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("got error %T, want *Error", err)
+			}
+			if want := filename + tt.path; e.Path != want {
+				t.Errorf("got path %q, want %q", e.Path, want)
+			}
+		})
+	}
+}
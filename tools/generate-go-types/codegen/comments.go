@@ -0,0 +1,161 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+const generatedHeader = "Code generated by generate-go-types. DO NOT EDIT."
+
+// constraints describes the validation keywords of a schema, e.g.
+// "minimum 1, pattern ^evt_[a-zA-Z0-9]+$".
+func constraints(schema *Schema) string {
+	var c []string
+	add := func(format string, args ...interface{}) {
+		c = append(c, fmt.Sprintf(format, args...))
+	}
+	if schema.Pattern != "" {
+		add("pattern %s", schema.Pattern)
+	}
+	if schema.MinLength > 0 {
+		add("min length %d", schema.MinLength)
+	}
+	if schema.MaxLength > 0 {
+		add("max length %d", schema.MaxLength)
+	}
+	if schema.Minimum != "" {
+		add("minimum %s", schema.Minimum)
+	}
+	if schema.ExclusiveMinimum != "" {
+		add("exclusive minimum %s", schema.ExclusiveMinimum)
+	}
+	if schema.Maximum != "" {
+		add("maximum %s", schema.Maximum)
+	}
+	if schema.ExclusiveMaximum != "" {
+		add("exclusive maximum %s", schema.ExclusiveMaximum)
+	}
+	if schema.MultipleOf != "" {
+		add("multiple of %s", schema.MultipleOf)
+	}
+	if schema.MinItems > 0 {
+		add("min items %d", schema.MinItems)
+	}
+	if schema.MaxItems > 0 {
+		add("max items %d", schema.MaxItems)
+	}
+	if schema.UniqueItems {
+		add("unique items")
+	}
+	if schema.Contains != nil {
+		var what []string
+		if len(schema.Contains.Type) > 0 {
+			what = append(what, string(schemaType(schema.Contains)))
+		}
+		if schema.Contains.Const != nil {
+			b, _ := json.Marshal(schema.Contains.Const)
+			what = append(what, "const "+string(b))
+		}
+		if c := constraints(schema.Contains); c != "" {
+			what = append(what, c)
+		}
+		if len(what) == 0 {
+			what = append(what, "any")
+		}
+		add("contains (%s)", strings.Join(what, ", "))
+	}
+	if schema.MinContains > 0 {
+		add("min contains %d", schema.MinContains)
+	}
+	if schema.MaxContains > 0 {
+		add("max contains %d", schema.MaxContains)
+	}
+	if schema.MinProperties > 0 {
+		add("min properties %d", schema.MinProperties)
+	}
+	if schema.MaxProperties > 0 {
+		add("max properties %d", schema.MaxProperties)
+	}
+	return strings.Join(c, ", ")
+}
+
+// docLines turns the annotations of a schema into doc comment lines, with
+// empty strings separating paragraphs. what names the thing being
+// documented in the deprecation notice.
+func docLines(schema *Schema, what string) []string {
+	var paragraphs [][]string
+	if schema.Title != "" {
+		paragraphs = append(paragraphs, []string{schema.Title})
+	}
+	if schema.Description != "" {
+		paragraphs = append(paragraphs, strings.Split(strings.TrimSpace(schema.Description), "\n"))
+	}
+
+	var details []string
+	if c := constraints(schema); c != "" {
+		details = append(details, "Constraints: "+c+".")
+	}
+	if schema.Default != nil {
+		if b, err := json.Marshal(schema.Default); err == nil {
+			details = append(details, "Default: "+string(b)+".")
+		}
+	}
+	if len(schema.Examples) > 0 {
+		var examples []string
+		for _, e := range schema.Examples {
+			b, err := json.Marshal(e)
+			if err == nil {
+				examples = append(examples, string(b))
+			}
+		}
+		label := "Examples: "
+		if len(examples) == 1 {
+			label = "Example: "
+		}
+		details = append(details, label+strings.Join(examples, ", "))
+	}
+	if len(details) > 0 {
+		paragraphs = append(paragraphs, details)
+	}
+
+	if schema.Deprecated {
+		paragraphs = append(paragraphs, []string{"Deprecated: this " + what + " is deprecated in the schema."})
+	}
+
+	var lines []string
+	for i, p := range paragraphs {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, p...)
+	}
+	return lines
+}
+
+// comment renders doc comment lines, one "//" comment per line.
+func comment(lines []string) *jen.Statement {
+	c := jen.Null()
+	for _, line := range lines {
+		if line == "" {
+			line = "//"
+		}
+		c.Comment(line).Line()
+	}
+	return c
+}
+
+// typeComment writes the doc comment for a type generated from schema.
+func typeComment(out *output, schema *Schema) {
+	if schema == nil {
+		return
+	}
+	for _, line := range docLines(schema, "type") {
+		if line == "" {
+			line = "//"
+		}
+		out.file.Comment(line)
+	}
+}
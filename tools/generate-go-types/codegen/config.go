@@ -0,0 +1,163 @@
+package codegen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// Config is the optional JSON configuration of a Generator.
+type Config struct {
+	// Formats maps a JSON schema "format" to the Go type used for strings
+	// with that format, replacing the built-in mapping.
+	Formats map[string]string `json:"formats"`
+	// Properties maps "<Type>.<property>", e.g. "Metadata.timestamp", to the
+	// Go type used for that struct field.
+	Properties map[string]string `json:"properties"`
+	// Tags maps the families of struct tags generated besides json, e.g.
+	// "yaml", "bson", "db", "msgpack" or "zog", to how they are written. If
+	// nil, zog tags are generated with the "lower" naming.
+	Tags map[string]TagConfig `json:"tags"`
+	// Initialisms are words written in upper case in Go identifiers, e.g.
+	// "ID" names the property "product_id" ProductID rather than ProductId.
+	Initialisms []string `json:"initialisms"`
+}
+
+// TagConfig configures a family of struct tags.
+type TagConfig struct {
+	// Naming is how the property name is written in the tag, one of the
+	// Naming* values. The property name is used as is if empty.
+	Naming string `json:"naming"`
+	// OmitEmpty adds the "omitempty" option to the tags of optional
+	// properties.
+	OmitEmpty bool `json:"omitempty"`
+}
+
+// Naming strategies for struct tags, e.g. for the property "orderURL".
+const (
+	NamingOriginal = "original" // orderURL
+	NamingSnake    = "snake"    // order_url
+	NamingKebab    = "kebab"    // order-url
+	NamingCamel    = "camel"    // orderUrl
+	NamingPascal   = "pascal"   // OrderUrl
+	NamingLower    = "lower"    // orderurl
+)
+
+// defaultTags are the tags generated besides json without configuration.
+var defaultTags = map[string]TagConfig{"zog": {Naming: NamingLower}}
+
+// name returns the tag for a property.
+func (c TagConfig) name(propName string, required bool) string {
+	words := splitWords(propName)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	var tag string
+	switch c.Naming {
+	case NamingSnake:
+		tag = strings.Join(words, "_")
+	case NamingKebab:
+		tag = strings.Join(words, "-")
+	case NamingCamel, NamingPascal:
+		for i, w := range words {
+			if i > 0 || c.Naming == NamingPascal {
+				w = strings.Title(w)
+			}
+			tag += w
+		}
+	case NamingLower:
+		tag = strings.Join(words, "")
+	default:
+		tag = propName
+	}
+	if c.OmitEmpty && !required {
+		tag += ",omitempty"
+	} else if tag == "-" {
+		// a bare "-" would skip the field
+		tag += ","
+	}
+	return tag
+}
+
+// validate checks the tag configuration.
+func (config Config) validate() error {
+	for family, tag := range config.Tags {
+		if family == "json" {
+			return errors.New("json tags always use the property names and can't be configured")
+		}
+		switch tag.Naming {
+		case "", NamingOriginal, NamingSnake, NamingKebab, NamingCamel, NamingPascal, NamingLower:
+		default:
+			return fmt.Errorf("unknown naming %q for %s tags", tag.Naming, family)
+		}
+	}
+	return nil
+}
+
+// tags returns the configured tag families.
+func (g *Generator) tags() map[string]TagConfig {
+	if g.opts.Config.Tags == nil {
+		return defaultTags
+	}
+	return g.opts.Config.Tags
+}
+
+// LoadConfig reads a JSON configuration file.
+func LoadConfig(filename string) (Config, error) {
+	var config Config
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return config, fmt.Errorf("failed to open config file: %w", err)
+	}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return config, fmt.Errorf("failed to load config JSON %q: %w", filename, err)
+	}
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("invalid config %q: %w", filename, err)
+	}
+	return config, nil
+}
+
+// parseGoType parses a Go type written as "[*][import/path.]Name", e.g.
+// "*github.com/google/uuid.UUID" or "time.Time".
+func parseGoType(s string) *jen.Statement {
+	t := jen.Add()
+	for strings.HasPrefix(s, "*") {
+		t = t.Op("*")
+		s = s[1:]
+	}
+	i := strings.LastIndex(s, ".")
+	if i < 0 {
+		return t.Id(s)
+	}
+	return t.Qual(s[:i], s[i+1:])
+}
+
+// splitWords splits a name into words at characters other than letters and
+// digits and at changes of case, e.g. "orderURL_v2" into "order", "URL" and
+// "v2".
+func splitWords(s string) []string {
+	var words []string
+	fields := strings.FieldsFunc(s, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	})
+	for _, field := range fields {
+		r := []rune(field)
+		start := 0
+		for i := 1; i < len(r); i++ {
+			// "orderURL" splits before "U", and "URLPath" before "P"
+			if !unicode.IsUpper(r[i-1]) && unicode.IsUpper(r[i]) ||
+				unicode.IsUpper(r[i-1]) && unicode.IsUpper(r[i]) && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+				words = append(words, string(r[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(r[start:]))
+	}
+	return words
+}
@@ -0,0 +1,179 @@
+package codegen
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// structDefaults collects how a struct's default and constant values are
+// set: init holds the fields of the struct literal its NewX constructor
+// starts from, and apply the statements of its ApplyDefaults method.
+type structDefaults struct {
+	init  jen.Dict
+	apply []jen.Code
+}
+
+func (d *structDefaults) empty() bool {
+	return len(d.init) == 0 && len(d.apply) == 0
+}
+
+// defaultValue returns the value a property is set to: its "const", the
+// only value of its "enum", or else its "default". constant reports whether
+// the value is the only one allowed.
+func defaultValue(schema *Schema) (v interface{}, constant bool) {
+	if schema.Const != nil {
+		return schema.Const, true
+	}
+	if len(schema.Enum) == 1 && schema.Enum[0] != nil {
+		return schema.Enum[0], true
+	}
+	return schema.Default, false
+}
+
+// defaultLiteral returns a Go literal of value for a field of type t and an
+// expression reporting whether field is unset. ok is false for values of
+// types without a literal, such as objects, arrays and formatted strings.
+func (g *Generator) defaultLiteral(schema *Schema, t jen.Code, value interface{}, field *jen.Statement, out *output) (lit, unset jen.Code, ok bool) {
+	goType := g.goSource(t)
+	if strings.ContainsAny(goType, "*[") || schema.GoType != "" {
+		return nil, nil, false
+	}
+	typ := schemaType(schema)
+	if _, enumType, ok := enumValues(schema); ok {
+		typ = enumType
+	}
+
+	switch typ {
+	case TypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, nil, false
+		}
+		if schema.Format != "" {
+			if ft, _ := g.formatType(schema.Format, out); ft != nil {
+				return nil, nil, false
+			}
+		}
+		return jen.Lit(s), field.Clone().Op("==").Lit(""), true
+	case TypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, nil, false
+		}
+		return jen.Lit(b), jen.Op("!").Add(field.Clone()), true
+	case TypeInteger:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return nil, nil, false
+		}
+		return jen.Lit(int(n)), field.Clone().Op("==").Lit(0), true
+	case TypeNumber:
+		n, ok := value.(float64)
+		if !ok {
+			return nil, nil, false
+		}
+		lit = jen.Lit(strconv.FormatFloat(n, 'f', -1, 64))
+		if goType == "json.Number" {
+			lit = jen.Qual("encoding/json", "Number").Call(lit)
+		}
+		return lit, field.Clone().Op("==").Lit(""), true
+	}
+	return nil, nil, false
+}
+
+// addDefaultField records how to set the default of the field id, of type t,
+// generated for the property schema. Optional fields with a default are set
+// by ApplyDefaults when unset, required ones by the constructor only, and
+// constant fields by ApplyDefaults, which the constructor calls. Fields holding structs with defaults of their own
+// are initialised with their constructor and have their ApplyDefaults
+// called.
+func (g *Generator) addDefaultField(d *structDefaults, id string, t jen.Code, schema *Schema, doc *document, required bool) {
+	field := jen.Id("v").Dot(id)
+
+	target := schema
+	if schema.Ref != "" {
+		target, _, _, _ = g.resolveRef(schema.Ref, doc)
+	}
+	value, constant := defaultValue(schema)
+	if value == nil {
+		value, constant = defaultValue(target)
+	}
+	if value != nil {
+		lit, unset, ok := g.defaultLiteral(target, t, value, field, doc.out)
+		if !ok {
+			return
+		}
+		if required && !constant {
+			d.init[jen.Id(id)] = lit
+		} else {
+			d.apply = append(d.apply, jen.If(unset).Block(field.Clone().Op("=").Add(lit)))
+		}
+		return
+	}
+
+	goType := g.goSource(t)
+	switch {
+	case g.defaulted[goType] != nil:
+		// optional structs which are not pointers are left alone, as
+		// applying their defaults would make them appear when encoded
+		if required {
+			d.init[jen.Id(id)] = jen.Add(g.defaulted[goType]).Call()
+			d.apply = append(d.apply, field.Clone().Dot("ApplyDefaults").Call())
+		}
+	case g.defaulted[strings.TrimPrefix(goType, "*")] != nil:
+		d.apply = append(d.apply, jen.If(field.Clone().Op("!=").Nil()).Block(
+			field.Clone().Dot("ApplyDefaults").Call(),
+		))
+	case g.defaulted[strings.TrimPrefix(goType, "[]")] != nil:
+		d.apply = append(d.apply, jen.For(jen.Id("i").Op(":=").Range().Add(field.Clone())).Block(
+			field.Clone().Index(jen.Id("i")).Dot("ApplyDefaults").Call(),
+		))
+	}
+}
+
+// addDefaultEmbed records an embedded struct of type t, which is set by its
+// constructor if it has defaults.
+func (g *Generator) addDefaultEmbed(d *structDefaults, t jen.Code) {
+	goType := g.goSource(t)
+	constructor := g.defaulted[goType]
+	if constructor == nil {
+		return
+	}
+	id := goType[strings.LastIndex(goType, ".")+1:]
+	d.init[jen.Id(id)] = jen.Add(constructor).Call()
+	d.apply = append(d.apply, jen.Id("v").Dot(id).Dot("ApplyDefaults").Call())
+}
+
+// defaultsShape describes the defaults d for the shape of a struct type, so
+// that structs with the same fields but different defaults are not folded
+// into one.
+func (g *Generator) defaultsShape(d *structDefaults) string {
+	if d.empty() {
+		return ""
+	}
+	return " defaults " + g.goSource(jen.Func().Id("defaults").Params().Block(append([]jen.Code{jen.Id("_").Op("=").Id("T").Values(d.init)}, d.apply...)...))
+}
+
+// generateDefaults emits the NewX constructor and ApplyDefaults method of
+// the struct name, if it has any defaults.
+func (g *Generator) generateDefaults(name string, d *structDefaults, out *output) {
+	if d.empty() {
+		return
+	}
+	g.defaulted[g.goSource(out.typeRef(name))] = out.typeRef("New" + name)
+
+	f := out.file
+	f.Commentf("New%s returns a new %s with its defaults set.", name, name)
+	f.Func().Id("New"+name).Params().Id(name).Block(
+		jen.Id("v").Op(":=").Id(name).Values(d.init),
+		jen.Id("v").Dot("ApplyDefaults").Call(),
+		jen.Return(jen.Id("v")),
+	).Line()
+
+	f.Comment("ApplyDefaults sets unset optional fields to their default values and")
+	f.Comment("constant fields to their only value, e.g. after decoding.")
+	f.Func().Params(jen.Id("v").Op("*").Id(name)).Id("ApplyDefaults").Params().Block(d.apply...).Line()
+}
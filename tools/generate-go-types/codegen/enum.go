@@ -0,0 +1,140 @@
+package codegen
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// enumValues returns the values a schema is restricted to by "enum" or
+// "const", if they can be represented as a Go string or integer enum.
+func enumValues(schema *Schema) ([]interface{}, Type, bool) {
+	values := schema.Enum
+	if schema.Const != nil {
+		values = []interface{}{schema.Const}
+	}
+	if len(values) == 0 {
+		return nil, "", false
+	}
+
+	t := schemaType(schema)
+	if t == TypeNumber && len(schema.Type) == 0 {
+		// untyped numeric enums are integer enums if every value is one
+		t = TypeInteger
+	}
+	for _, v := range values {
+		switch t {
+		case TypeString:
+			if _, ok := v.(string); !ok {
+				return nil, "", false
+			}
+		case TypeInteger:
+			n, ok := v.(float64)
+			if !ok || n != math.Trunc(n) {
+				return nil, "", false
+			}
+		default:
+			return nil, "", false
+		}
+	}
+	return values, t, true
+}
+
+// enumConstName names the constant for one enum value, e.g.
+// ShippingMethodExpress.
+func (g *Generator) enumConstName(typeName string, v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = g.formatWords(v)
+		if s == "" {
+			s = "Empty"
+		}
+	case float64:
+		if v < 0 {
+			s = "Minus"
+		}
+		s += strconv.FormatInt(int64(math.Abs(v)), 10)
+	}
+	return typeName + s
+}
+
+// generateEnum emits a named string or integer type with one constant per
+// value, along with Valid, String and UnmarshalJSON methods. UnmarshalJSON
+// rejects values outside the enum.
+func (g *Generator) generateEnum(name string, schema *Schema, t Type, values []interface{}, out *output, reuse bool) string {
+	b, err := json.Marshal(values)
+	if err != nil {
+		g.fail(ErrLoad, "invalid enum for %q: %v", name, err)
+	}
+	shape := "enum " + string(t) + " " + string(b)
+
+	return g.declareType(name, shape, out, reuse, func() {
+		f := out.file
+
+		base := jen.String()
+		if t == TypeInteger {
+			base = jen.Int64()
+		}
+		typeComment(out, schema)
+		f.Type().Id(name).Add(base).Line()
+
+		var consts, cases []jen.Code
+		seen := make(map[string]bool)
+		for _, v := range values {
+			constName := g.enumConstName(name, v)
+			if seen[constName] {
+				g.fail(ErrNameCollision, "enum %q has several values named %q", name, constName)
+			}
+			seen[constName] = true
+
+			var lit jen.Code
+			if t == TypeInteger {
+				lit = jen.Lit(int(v.(float64)))
+			} else {
+				lit = jen.Lit(v.(string))
+			}
+			consts = append(consts, jen.Id(constName).Id(name).Op("=").Add(lit))
+			cases = append(cases, jen.Id(constName))
+		}
+		f.Const().Defs(consts...).Line()
+
+		f.Commentf("Valid reports whether v is one of the %s values.", name)
+		f.Func().Params(jen.Id("v").Id(name)).Id("Valid").Params().Bool().Block(
+			jen.Switch(jen.Id("v")).Block(
+				jen.Case(cases...).Block(jen.Return(jen.True())),
+			),
+			jen.Return(jen.False()),
+		).Line()
+
+		var str, decoded jen.Code
+		var verb string
+		if t == TypeInteger {
+			str = jen.Qual("strconv", "FormatInt").Call(jen.Int64().Call(jen.Id("v")), jen.Lit(10))
+			decoded = jen.Int64()
+			verb = "%d"
+		} else {
+			str = jen.String().Call(jen.Id("v"))
+			decoded = jen.String()
+			verb = "%q"
+		}
+		f.Func().Params(jen.Id("v").Id(name)).Id("String").Params().String().Block(
+			jen.Return(str),
+		).Line()
+
+		f.Func().Params(jen.Id("v").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+			jen.Var().Id("raw").Add(decoded),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("raw")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.If(jen.Op("!").Id(name).Call(jen.Id("raw")).Dot("Valid").Call()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("invalid "+name+" "+verb), jen.Id("raw"))),
+			),
+			jen.Op("*").Id("v").Op("=").Id(name).Call(jen.Id("raw")),
+			jen.Return(jen.Nil()),
+		).Line()
+	})
+}
@@ -0,0 +1,189 @@
+package codegen
+
+import (
+	"time"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// formatType returns the Go type used for a string with the given format,
+// or nil if it is a plain string. pointer reports whether the type has no
+// useful zero value for omitempty, and so should be a pointer when the
+// property is optional.
+func (g *Generator) formatType(format string, out *output) (t *jen.Statement, pointer bool) {
+	if goType, ok := g.opts.Config.Formats[format]; ok {
+		return parseGoType(goType), false
+	}
+	if g.opts.NoFormats {
+		return nil, false
+	}
+
+	switch format {
+	case "date-time":
+		return jen.Qual("time", "Time"), true
+	case "date":
+		return out.typeRef(g.generateDateType(out)), true
+	case "uuid":
+		return out.typeRef(g.generateUUIDType(out)), true
+	case "uri":
+		return out.typeRef(g.generateURLType(out)), true
+	case "duration":
+		return out.typeRef(g.generateDurationType(out)), false
+	}
+	return nil, false
+}
+
+// textMethods emits MarshalText and UnmarshalText for a helper type; the
+// receiver is named v.
+func textMethods(f *jen.File, name string, marshal []jen.Code, unmarshal []jen.Code) {
+	f.Func().Params(jen.Id("v").Id(name)).Id("MarshalText").Params().Params(jen.Index().Byte(), jen.Error()).Block(marshal...).Line()
+	f.Func().Params(jen.Id("v").Op("*").Id(name)).Id("UnmarshalText").Params(jen.Id("b").Index().Byte()).Error().Block(unmarshal...).Line()
+}
+
+func (g *Generator) generateDateType(out *output) string {
+	const name = "Date"
+	return g.declareType(name, "format date", out, false, func() {
+		f := out.file
+		f.Comment(`Date is a calendar date without a time zone, encoded as "2006-01-02".`)
+		f.Type().Id(name).Struct(
+			jen.Id("Year").Int(),
+			jen.Id("Month").Qual("time", "Month"),
+			jen.Id("Day").Int(),
+		).Line()
+
+		f.Func().Params(jen.Id("v").Id(name)).Id("String").Params().String().Block(
+			jen.Return(jen.Qual("fmt", "Sprintf").Call(jen.Lit("%04d-%02d-%02d"), jen.Id("v").Dot("Year"), jen.Id("v").Dot("Month"), jen.Id("v").Dot("Day"))),
+		).Line()
+
+		textMethods(f, name, []jen.Code{
+			jen.Return(jen.Index().Byte().Call(jen.Id("v").Dot("String").Call()), jen.Nil()),
+		}, []jen.Code{
+			jen.List(jen.Id("t"), jen.Err()).Op(":=").Qual("time", "Parse").Call(jen.Lit(time.DateOnly), jen.String().Call(jen.Id("b"))),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.Op("*").Id("v").Op("=").Id(name).Values(jen.Dict{
+				jen.Id("Year"):  jen.Id("t").Dot("Year").Call(),
+				jen.Id("Month"): jen.Id("t").Dot("Month").Call(),
+				jen.Id("Day"):   jen.Id("t").Dot("Day").Call(),
+			}),
+			jen.Return(jen.Nil()),
+		})
+	})
+}
+
+func (g *Generator) generateUUIDType(out *output) string {
+	const name = "UUID"
+	return g.declareType(name, "format uuid", out, false, func() {
+		f := out.file
+		f.Comment("UUID is a UUID encoded in its canonical textual form.")
+		f.Type().Id(name).Index(jen.Lit(16)).Byte().Line()
+
+		f.Func().Params(jen.Id("v").Id(name)).Id("String").Params().String().Block(
+			jen.Return(jen.Qual("fmt", "Sprintf").Call(
+				jen.Lit("%x-%x-%x-%x-%x"),
+				jen.Id("v").Index(jen.Lit(0), jen.Lit(4)),
+				jen.Id("v").Index(jen.Lit(4), jen.Lit(6)),
+				jen.Id("v").Index(jen.Lit(6), jen.Lit(8)),
+				jen.Id("v").Index(jen.Lit(8), jen.Lit(10)),
+				jen.Id("v").Index(jen.Lit(10), jen.Empty()),
+			)),
+		).Line()
+
+		invalid := jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("invalid UUID %q"), jen.Id("b")))
+		textMethods(f, name, []jen.Code{
+			jen.Return(jen.Index().Byte().Call(jen.Id("v").Dot("String").Call()), jen.Nil()),
+		}, []jen.Code{
+			jen.If(jen.Len(jen.Id("b")).Op("!=").Lit(36).Op("||").
+				Id("b").Index(jen.Lit(8)).Op("!=").LitRune('-').Op("||").
+				Id("b").Index(jen.Lit(13)).Op("!=").LitRune('-').Op("||").
+				Id("b").Index(jen.Lit(18)).Op("!=").LitRune('-').Op("||").
+				Id("b").Index(jen.Lit(23)).Op("!=").LitRune('-'),
+			).Block(invalid),
+			jen.List(jen.Id("raw"), jen.Err()).Op(":=").Qual("encoding/hex", "DecodeString").Call(
+				jen.Qual("strings", "ReplaceAll").Call(jen.String().Call(jen.Id("b")), jen.Lit("-"), jen.Lit("")),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(invalid),
+			jen.Copy(jen.Id("v").Index(jen.Empty(), jen.Empty()), jen.Id("raw")),
+			jen.Return(jen.Nil()),
+		})
+	})
+}
+
+func (g *Generator) generateURLType(out *output) string {
+	const name = "URL"
+	return g.declareType(name, "format uri", out, false, func() {
+		f := out.file
+		f.Comment("URL is a URL encoded as a string.")
+		f.Type().Id(name).Struct(jen.Qual("net/url", "URL")).Line()
+
+		textMethods(f, name, []jen.Code{
+			jen.Return(jen.Index().Byte().Call(jen.Id("v").Dot("String").Call()), jen.Nil()),
+		}, []jen.Code{
+			jen.List(jen.Id("u"), jen.Err()).Op(":=").Qual("net/url", "Parse").Call(jen.String().Call(jen.Id("b"))),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.Id("v").Dot("URL").Op("=").Op("*").Id("u"),
+			jen.Return(jen.Nil()),
+		})
+	})
+}
+
+func (g *Generator) generateDurationType(out *output) string {
+	const name = "Duration"
+	return g.declareType(name, "format duration", out, false, func() {
+		f := out.file
+		pattern := "durationPattern"
+		f.Var().Id(pattern).Op("=").Qual("regexp", "MustCompile").Call(
+			jen.Lit(`^(-)?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`),
+		).Line()
+
+		f.Comment(`Duration is a time.Duration encoded as an ISO 8601 duration such as`)
+		f.Comment(`"PT1H30M". Years and months are not supported as their length varies.`)
+		f.Type().Id(name).Qual("time", "Duration").Line()
+
+		d := jen.Qual("time", "Duration")
+		f.Func().Params(jen.Id("v").Id(name)).Id("String").Params().String().Block(
+			jen.Id("d").Op(":=").Add(d).Call(jen.Id("v")),
+			jen.Id("s").Op(":=").Lit("PT"),
+			jen.If(jen.Id("d").Op("<").Lit(0)).Block(
+				jen.List(jen.Id("s"), jen.Id("d")).Op("=").List(jen.Lit("-PT"), jen.Op("-").Id("d")),
+			),
+			jen.If(jen.Id("h").Op(":=").Id("d").Op("/").Qual("time", "Hour"), jen.Id("h").Op(">").Lit(0)).Block(
+				jen.Id("s").Op("+=").Qual("strconv", "FormatInt").Call(jen.Int64().Call(jen.Id("h")), jen.Lit(10)).Op("+").Lit("H"),
+				jen.Id("d").Op("-=").Id("h").Op("*").Qual("time", "Hour"),
+			),
+			jen.If(jen.Id("m").Op(":=").Id("d").Op("/").Qual("time", "Minute"), jen.Id("m").Op(">").Lit(0)).Block(
+				jen.Id("s").Op("+=").Qual("strconv", "FormatInt").Call(jen.Int64().Call(jen.Id("m")), jen.Lit(10)).Op("+").Lit("M"),
+				jen.Id("d").Op("-=").Id("m").Op("*").Qual("time", "Minute"),
+			),
+			jen.If(jen.Id("d").Op(">").Lit(0).Op("||").Qual("strings", "HasSuffix").Call(jen.Id("s"), jen.Lit("T"))).Block(
+				jen.Id("s").Op("+=").Qual("strconv", "FormatFloat").Call(jen.Id("d").Dot("Seconds").Call(), jen.LitRune('f'), jen.Lit(-1), jen.Lit(64)).Op("+").Lit("S"),
+			),
+			jen.Return(jen.Id("s")),
+		).Line()
+
+		invalid := jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("invalid duration %q"), jen.Id("b")))
+		part := func(i int, unit jen.Code) jen.Code {
+			return jen.If(jen.Id("m").Index(jen.Lit(i)).Op("!=").Lit("")).Block(
+				jen.List(jen.Id("n"), jen.Err()).Op(":=").Qual("strconv", "ParseFloat").Call(jen.Id("m").Index(jen.Lit(i)), jen.Lit(64)),
+				jen.If(jen.Err().Op("!=").Nil()).Block(invalid),
+				jen.Id("d").Op("+=").Add(d).Call(jen.Id("n").Op("*").Float64().Call(unit)),
+			)
+		}
+		textMethods(f, name, []jen.Code{
+			jen.Return(jen.Index().Byte().Call(jen.Id("v").Dot("String").Call()), jen.Nil()),
+		}, []jen.Code{
+			jen.Id("m").Op(":=").Id(pattern).Dot("FindStringSubmatch").Call(jen.String().Call(jen.Id("b"))),
+			jen.If(jen.Id("m").Op("==").Nil().Op("||").Qual("strings", "HasSuffix").Call(jen.String().Call(jen.Id("b")), jen.Lit("P")).Op("||").Qual("strings", "HasSuffix").Call(jen.String().Call(jen.Id("b")), jen.Lit("T"))).Block(invalid),
+			jen.Var().Id("d").Add(d),
+			part(2, jen.Lit(7).Op("*").Lit(24).Op("*").Qual("time", "Hour")),
+			part(3, jen.Lit(24).Op("*").Qual("time", "Hour")),
+			part(4, jen.Qual("time", "Hour")),
+			part(5, jen.Qual("time", "Minute")),
+			part(6, jen.Qual("time", "Second")),
+			jen.If(jen.Id("m").Index(jen.Lit(1)).Op("!=").Lit("")).Block(
+				jen.Id("d").Op("=").Op("-").Id("d"),
+			),
+			jen.Op("*").Id("v").Op("=").Id(name).Call(jen.Id("d")),
+			jen.Return(jen.Nil()),
+		})
+	})
+}
@@ -0,0 +1,441 @@
+package codegen
+
+import (
+	"bytes"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// formatId turns a name into an exported Go identifier, e.g. "order_id"
+// into OrderId, or OrderID with the "ID" initialism configured. The plural of
+// an initialism keeps a lowercase s, e.g. "customer_ids" becomes CustomerIDs.
+// Names which don't start with a letter are prefixed with X, e.g. "1st"
+// becomes X1st and "-" becomes X.
+func (g *Generator) formatId(s string) string {
+	id := g.formatWords(s)
+	if r, _ := utf8.DecodeRuneInString(id); !unicode.IsLetter(r) {
+		id = "X" + id
+	}
+	return id
+}
+
+// formatWords is formatId for a name following a prefix, e.g. "1st" becomes
+// 1st. It is empty for names without letters or digits.
+func (g *Generator) formatWords(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if initialism, ok := g.initialisms[strings.ToUpper(word)]; ok {
+			b.WriteString(initialism)
+		} else if initialism, ok := g.initialisms[strings.ToUpper(strings.TrimSuffix(word, "s"))]; ok && strings.HasSuffix(word, "s") {
+			b.WriteString(initialism + "s")
+		} else {
+			b.WriteString(strings.Title(word))
+		}
+	}
+	// numbers such as "²" are not digits, which identifiers are limited to
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, b.String())
+}
+
+func schemaType(schema *Schema) Type {
+	switch {
+	case len(schema.Type) == 1:
+		return schema.Type[0]
+	case len(schema.Type) > 0:
+		return ""
+	}
+
+	var v interface{}
+	if schema.Const != nil {
+		v = schema.Const
+	} else if len(schema.Enum) > 0 {
+		v = schema.Enum[0]
+	}
+
+	switch v.(type) {
+	case bool:
+		return TypeBoolean
+	case map[string]interface{}:
+		return TypeObject
+	case []interface{}:
+		return TypeArray
+	case float64:
+		return TypeNumber
+	case string:
+		return TypeString
+	default:
+		return ""
+	}
+}
+
+func isRequired(schema *Schema, propName string) bool {
+	for _, name := range schema.Required {
+		if name == propName {
+			return true
+		}
+	}
+	return false
+}
+
+// declareType declares a type unless one with the same shape already exists
+// under name. If reuse is set, a nested type whose shape matches a
+// previously emitted type is folded into it and the existing name is
+// returned. Otherwise emit is called to write the declaration.
+func (g *Generator) declareType(name, shape string, out *output, reuse bool, emit func()) string {
+	if reuse {
+		if existing, ok := out.shapes[shape]; ok {
+			return existing
+		}
+	}
+	if existing, ok := out.types[name]; ok {
+		if existing != shape {
+			g.fail(ErrNameCollision, "type name %q is generated for two different schemas; set a distinct \"title\" on one of them", name)
+		}
+		return name
+	}
+	out.types[name] = shape
+	if _, ok := out.shapes[shape]; !ok {
+		out.shapes[shape] = name
+	}
+	emit()
+	return name
+}
+
+// goSource returns the Go source of code, failing if it doesn't format,
+// e.g. for a configured Go type which doesn't parse.
+func (g *Generator) goSource(code jen.Code) string {
+	var b bytes.Buffer
+	if err := jen.Add(code).Render(&b); err != nil {
+		g.fail(ErrInvalidCode, "%v", err)
+	}
+	return b.String()
+}
+
+// declareDef declares "type name def", documented by the annotations of
+// schema, using the Go source of def as its shape.
+func (g *Generator) declareDef(name string, schema *Schema, def *jen.Statement, out *output, reuse bool) string {
+	return g.declareType(name, g.goSource(def), out, reuse, func() {
+		typeComment(out, schema)
+		out.file.Type().Id(name).Add(def).Line()
+	})
+}
+
+// fieldName names the struct field for a property: its "x-go-name", or else
+// the property name as a Go identifier.
+func (g *Generator) fieldName(propName string, prop *Schema) string {
+	if prop != nil && prop.GoName != "" {
+		if !token.IsIdentifier(prop.GoName) {
+			g.fail(ErrInvalidName, "\"x-go-name\" %q is not a Go identifier", prop.GoName)
+		}
+		return prop.GoName
+	}
+	return g.formatId(propName)
+}
+
+// nestedTypeName names an inline object schema: its title when present,
+// otherwise the parent type name, if any, followed by the property name.
+func (g *Generator) nestedTypeName(parent, propName string, schema *Schema) string {
+	if schema.Title != "" {
+		return g.formatId(schema.Title)
+	}
+	if suffix := g.formatWords(propName); parent != "" && suffix != "" {
+		return parent + suffix
+	}
+	return parent + g.formatId(propName)
+}
+
+// generateStruct emits a struct type for schema. Nested types are named
+// with scope as their prefix, which is empty for the root schema.
+func (g *Generator) generateStruct(name, scope string, schema *Schema, doc *document, reuse bool) string {
+	fields, defaults := g.structFields(name, scope, schema, doc)
+	extra, value := g.extraField(name, scope, schema, doc)
+	if extra != nil {
+		fields = append(fields, extra)
+	}
+
+	def := jen.Struct(fields...)
+	return g.declareType(name, g.goSource(def)+g.defaultsShape(defaults), doc.out, reuse, func() {
+		typeComment(doc.out, schema)
+		doc.out.file.Type().Id(name).Add(def).Line()
+		if extra != nil {
+			g.generateExtraMethods(name, schema, value, doc.out)
+		}
+		g.generateDefaults(name, defaults, doc.out)
+	})
+}
+
+// sortedProps returns the property names of schema in order.
+func sortedProps(schema *Schema) []string {
+	var propNames []string
+	for p := range schema.Properties {
+		propNames = append(propNames, p)
+	}
+	sort.Strings(propNames)
+	return propNames
+}
+
+// structFields returns a struct field for every property of schema, and
+// how to set their defaults.
+func (g *Generator) structFields(name, scope string, schema *Schema, doc *document) ([]jen.Code, *structDefaults) {
+	var fields []jen.Code
+	defaults := &structDefaults{init: jen.Dict{}}
+	seen := make(map[string]string)
+	for _, propName := range sortedProps(schema) {
+		restore := g.at("properties", propName)
+		prop := schema.Properties[propName]
+		id := g.fieldName(propName, &prop)
+		if other, ok := seen[id]; ok {
+			g.fail(ErrNameCollision, "properties %q and %q of %s are both named %s; set \"x-go-name\" on one of them", other, propName, name, id)
+		}
+		seen[id] = propName
+		required := isRequired(schema, propName)
+
+		// determine Go type
+		var t jen.Code
+		if goType, ok := g.opts.Config.Properties[name+"."+propName]; ok {
+			t = parseGoType(goType)
+			// render it here to report an invalid type at its property
+			g.goSource(t)
+		} else {
+			t = g.generateSchemaType(scope, propName, &prop, doc, required)
+			g.addDefaultField(defaults, id, t, &prop, doc, required)
+		}
+
+		// json tag
+		jsonTag := propName
+		if _, nullable := nullableSchema(&prop); nullable && g.opts.Nullable && !required {
+			jsonTag += ",omitzero"
+		} else if !required {
+			jsonTag += ",omitempty"
+		} else if jsonTag == "-" {
+			// a bare "-" would skip the field
+			jsonTag += ","
+		}
+		tags := map[string]string{"json": jsonTag}
+		for family, tag := range g.tags() {
+			tags[family] = tag.name(propName, required)
+		}
+		fields = append(fields, comment(docLines(&prop, "property")).Id(id).Add(t).Tag(tags))
+		restore()
+	}
+	return fields, defaults
+}
+
+func singlePatternProp(schema *Schema) *Schema {
+	if len(schema.PatternProperties) != 1 {
+		return nil
+	}
+	for _, prop := range schema.PatternProperties {
+		return &prop
+	}
+	return nil
+}
+
+func noAdditionalProps(schema *Schema) bool {
+	return schema.AdditionalProperties != nil && schema.AdditionalProperties.IsFalse()
+}
+
+// unwrapNullableSchema unwraps a schema in the form:
+//
+//	{
+//		"oneOf": {
+//			{ "type": "null" },
+//			<sub-schema>
+//		}
+//	}
+func unwrapNullableSchema(schema *Schema) (*Schema, bool) {
+	for _, choices := range [][]Schema{schema.AnyOf, schema.OneOf} {
+		if len(choices) != 2 {
+			continue
+		}
+
+		nullIndex := -1
+		for i, choice := range choices {
+			if len(choice.Type) == 1 && choice.Type[0] == TypeNull {
+				nullIndex = i
+				break
+			}
+		}
+		if nullIndex < 0 {
+			continue
+		}
+
+		otherIndex := (nullIndex + 1) % 2
+		return &choices[otherIndex], true
+	}
+	return nil, false
+}
+
+// nestedType returns the type of the nested schema at the current location
+// of doc, calling generate to declare it and return its name the first time.
+// Types are recorded by location like those of $refs, so that a $ref by
+// JSON pointer to a nested schema reuses its type, and the other way round.
+func (g *Generator) nestedType(doc *document, generate func() string) *jen.Statement {
+	key := doc.base.String() + "#" + g.pointer
+	if t, ok := g.refTypes[key]; ok {
+		return jen.Add(t)
+	}
+	t := doc.out.typeRef(generate())
+	g.refTypes[key] = t
+	return jen.Add(t)
+}
+
+func (g *Generator) generateSchemaType(parent, propName string, schema *Schema, doc *document, required bool) jen.Code {
+	if schema == nil {
+		schema = &Schema{}
+	}
+
+	if schema.GoType != "" {
+		return parseGoType(schema.GoType)
+	}
+
+	if schema.Ref != "" {
+		target, targetDoc, key, name := g.resolveRef(schema.Ref, doc)
+		schema = target
+		// a struct can only contain itself through a pointer
+		recursive := g.pending[key] && isObjectLike(schema)
+		t := jen.Add(g.generateRefType(key, name, target, targetDoc))
+		optionalStruct := schemaType(schema) == TypeObject && noAdditionalProps(schema) && len(schema.PatternProperties) == 0 ||
+			schemaType(schema) == TypeArray && len(schema.PrefixItems) > 0
+		if recursive || !required && optionalStruct {
+			t = jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if subschema, ok := nullableSchema(schema); ok {
+		return g.generateNullableType(parent, propName, subschema, doc, required)
+	}
+
+	if len(schema.Type) > 1 {
+		t := g.nestedType(doc, func() string {
+			return g.generateMultiType(g.nestedTypeName(parent, propName, schema), schema, doc, schema.Title == "")
+		})
+		if !required {
+			t = jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if u, ok := g.discriminatedUnion(schema, doc); ok {
+		t := g.nestedType(doc, func() string {
+			return g.generateUnion(g.nestedTypeName(parent, propName, schema), schema, u, doc, schema.Title == "")
+		})
+		if !required {
+			t = jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if g.isAllOfObject(schema, doc) {
+		t := g.nestedType(doc, func() string {
+			name := g.nestedTypeName(parent, propName, schema)
+			return g.generateAllOf(name, name, schema, doc, schema.Title == "")
+		})
+		if !required {
+			t = jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if values, t, ok := enumValues(schema); ok {
+		return g.nestedType(doc, func() string {
+			return g.generateEnum(g.nestedTypeName(parent, propName, schema), schema, t, values, doc.out, schema.Title == "")
+		})
+	}
+
+	switch schemaType(schema) {
+	case TypeNull:
+		return jen.Struct()
+	case TypeBoolean:
+		return jen.Bool()
+	case TypeNumber:
+		return jen.Qual("encoding/json", "Number")
+	case TypeString:
+		if t, pointer := g.formatType(schema.Format, doc.out); t != nil {
+			if pointer && !required {
+				t = jen.Op("*").Add(t)
+			}
+			return t
+		}
+		return jen.String()
+	case TypeInteger:
+		return jen.Int64()
+	case TypeObject:
+		if t := g.mapType(parent, propName, schema, doc); t != nil {
+			return t
+		}
+		nestedName := g.nestedTypeName(parent, propName, schema)
+		if isPatternMap(schema) {
+			return g.nestedType(doc, func() string {
+				return g.generatePatternMap(nestedName, nestedName, schema, doc, schema.Title == "")
+			})
+		}
+		typeCode := g.nestedType(doc, func() string {
+			return g.generateStruct(nestedName, nestedName, schema, doc, schema.Title == "")
+		})
+		if !required {
+			typeCode = jen.Op("*").Add(typeCode)
+		}
+		return typeCode
+	case TypeArray:
+		if len(schema.PrefixItems) > 0 {
+			t := g.nestedType(doc, func() string {
+				return g.generateTuple(g.nestedTypeName(parent, propName, schema), schema, doc, schema.Title == "")
+			})
+			if !required {
+				t = jen.Op("*").Add(t)
+			}
+			return t
+		}
+		defer g.at("items")()
+		items := g.generateSchemaType(parent, propName, schema.Items, doc, true)
+		if g.opts.Sets && schema.UniqueItems && g.orderedItems(schema.Items, doc) {
+			return doc.out.typeRef(g.generateSetType(doc.out)).Types(items)
+		}
+		return jen.Index().Add(items)
+	default:
+		return jen.Qual("encoding/json", "RawMessage")
+	}
+}
+
+func (g *Generator) generateDef(schema *Schema, doc *document, name string) {
+	id := g.formatId(name)
+	// types nested in the root schema are not prefixed with "Root"
+	scope := id
+	if schema == doc.schema {
+		scope = ""
+	}
+	// for root and defs, use named struct generator
+	if u, ok := g.discriminatedUnion(schema, doc); ok {
+		g.generateUnion(id, schema, u, doc, false)
+	} else if g.isAllOfObject(schema, doc) {
+		g.generateAllOf(id, scope, schema, doc, false)
+	} else if _, ok := nullableSchema(schema); !ok && len(schema.Type) > 1 {
+		g.generateMultiType(id, schema, doc, false)
+	} else if schemaType(schema) == TypeObject {
+		if t := g.mapType(scope, name, schema, doc); t != nil {
+			g.declareDef(id, schema, t, doc.out, false)
+		} else if isPatternMap(schema) {
+			g.generatePatternMap(id, scope, schema, doc, false)
+		} else {
+			g.generateStruct(id, scope, schema, doc, false)
+		}
+	} else if values, t, ok := enumValues(schema); ok {
+		g.generateEnum(id, schema, t, values, doc.out, false)
+	} else if schemaType(schema) == TypeArray && len(schema.PrefixItems) > 0 {
+		g.generateTuple(id, schema, doc, false)
+	} else {
+		// unchanged: alias simple types
+		g.declareDef(id, schema, jen.Add(g.generateSchemaType(scope, name, schema, doc, true)), doc.out, false)
+	}
+}
@@ -0,0 +1,160 @@
+package codegen
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// typedAdditionalProps returns the schema of additional properties if it
+// constrains their values. Absent, true and {} allow anything and are
+// ignored, as is false.
+func typedAdditionalProps(schema *Schema) *Schema {
+	ap := schema.AdditionalProperties
+	if ap == nil || ap.IsFalse() || reflect.DeepEqual(*ap, Schema{}) {
+		return nil
+	}
+	return ap
+}
+
+// mapType returns map[string]T for an object without fixed properties whose
+// additionalProperties has a type.
+func (g *Generator) mapType(parent, propName string, schema *Schema, doc *document) *jen.Statement {
+	ap := typedAdditionalProps(schema)
+	if ap == nil || len(schema.Properties) > 0 || len(schema.PatternProperties) > 0 {
+		return nil
+	}
+	defer g.at("additionalProperties")()
+	return jen.Map(jen.String()).Add(g.generateSchemaType(parent, propName+"_value", ap, doc, true))
+}
+
+// isPatternMap reports whether schema is an object whose only properties
+// match a single patternProperties entry.
+func isPatternMap(schema *Schema) bool {
+	return len(schema.Properties) == 0 && singlePatternProp(schema) != nil && typedAdditionalProps(schema) == nil
+}
+
+// generatePatternMap emits a named map type whose UnmarshalJSON rejects
+// keys not matching the pattern.
+func (g *Generator) generatePatternMap(name, scope string, schema *Schema, doc *document, reuse bool) string {
+	var pattern string
+	for p := range schema.PatternProperties {
+		pattern = p
+	}
+	restore := g.at("patternProperties", pattern)
+	value := g.generateSchemaType(scope, "value", singlePatternProp(schema), doc, true)
+	restore()
+	def := jen.Map(jen.String()).Add(value)
+
+	return g.declareType(name, "pattern map "+pattern+" "+g.goSource(def), doc.out, reuse, func() {
+		f := doc.out.file
+		patternVar := strings.ToLower(name[:1]) + name[1:] + "KeyPattern"
+		f.Var().Id(patternVar).Op("=").Qual("regexp", "MustCompile").Call(jen.Lit(pattern)).Line()
+
+		typeComment(doc.out, schema)
+		if len(docLines(schema, "type")) > 0 {
+			f.Comment("//")
+		}
+		f.Commentf("%s only holds keys matching %s.", name, pattern)
+		f.Type().Id(name).Add(def).Line()
+
+		f.Func().Params(jen.Id("m").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+			jen.Var().Id("raw").Add(def),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("raw")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.For(jen.Id("k").Op(":=").Range().Id("raw")).Block(
+				jen.If(jen.Op("!").Id(patternVar).Dot("MatchString").Call(jen.Id("k"))).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("invalid "+name+" key %q"), jen.Id("k"))),
+				),
+			),
+			jen.Op("*").Id("m").Op("=").Id(name).Call(jen.Id("raw")),
+			jen.Return(jen.Nil()),
+		).Line()
+	})
+}
+
+// generateExtraMethods emits MarshalJSON and UnmarshalJSON for a struct
+// with fixed properties whose other properties are kept in its Extra field.
+func (g *Generator) generateExtraMethods(name string, schema *Schema, value jen.Code, out *output) {
+	f := out.file
+	out.methods[name+".MarshalJSON"] = true
+	out.methods[name+".UnmarshalJSON"] = true
+
+	var known []jen.Code
+	for _, propName := range sortedProps(schema) {
+		known = append(known, jen.Lit(propName))
+	}
+
+	f.Func().Params(jen.Id("v").Id(name)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.Type().Id("raw").Id(name),
+		jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(jen.Id("raw").Call(jen.Id("v"))),
+		jen.If(jen.Err().Op("!=").Nil().Op("||").Len(jen.Id("v").Dot("Extra")).Op("==").Lit(0)).Block(
+			jen.Return(jen.Id("b"), jen.Err()),
+		),
+		jen.Var().Id("m").Map(jen.String()).Qual("encoding/json", "RawMessage"),
+		jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("m")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Nil(), jen.Err())),
+		jen.For(jen.List(jen.Id("k"), jen.Id("e")).Op(":=").Range().Id("v").Dot("Extra")).Block(
+			jen.Comment("fixed properties take precedence"),
+			jen.If(jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Id("m").Index(jen.Id("k")), jen.Id("ok")).Block(
+				jen.Continue(),
+			),
+			jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(jen.Id("e")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.Id("m").Index(jen.Id("k")).Op("=").Id("b"),
+		),
+		jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("m"))),
+	).Line()
+
+	f.Func().Params(jen.Id("v").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+		jen.Type().Id("raw").Id(name),
+		jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Parens(jen.Op("*").Id("raw")).Call(jen.Id("v"))),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())),
+		jen.Var().Id("m").Map(jen.String()).Qual("encoding/json", "RawMessage"),
+		jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("m")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())),
+		jen.For(jen.List(jen.Id("_"), jen.Id("k")).Op(":=").Range().Index().String().Values(known...)).Block(
+			jen.Delete(jen.Id("m"), jen.Id("k")),
+		),
+		jen.Id("v").Dot("Extra").Op("=").Nil(),
+		jen.For(jen.List(jen.Id("k"), jen.Id("e")).Op(":=").Range().Id("m")).Block(
+			jen.Var().Id("value").Add(value),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("e"), jen.Op("&").Id("value")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.If(jen.Id("v").Dot("Extra").Op("==").Nil()).Block(
+				jen.Id("v").Dot("Extra").Op("=").Make(jen.Map(jen.String()).Add(value), jen.Len(jen.Id("m"))),
+			),
+			jen.Id("v").Dot("Extra").Index(jen.Id("k")).Op("=").Id("value"),
+		),
+		jen.Return(jen.Nil()),
+	).Line()
+}
+
+// extraField returns the Extra field holding additional properties of a
+// struct with fixed properties, or nil if it has none.
+func (g *Generator) extraField(name, scope string, schema *Schema, doc *document) (field, value jen.Code) {
+	ap := typedAdditionalProps(schema)
+	if ap == nil || len(schema.Properties) == 0 {
+		return nil, nil
+	}
+	for propName, prop := range schema.Properties {
+		if g.fieldName(propName, &prop) == "Extra" {
+			g.fail(ErrNameCollision, "property %q of %s conflicts with the Extra field holding its additionalProperties", propName, name)
+		}
+	}
+	defer g.at("additionalProperties")()
+	value = g.generateSchemaType(scope, "extra", ap, doc, true)
+	field = jen.Comment("Extra holds the properties not declared in the schema.").Line().Id("Extra").Map(jen.String()).Add(value).Tag(map[string]string{"json": "-"})
+	return field, value
+}
@@ -0,0 +1,208 @@
+package codegen
+
+import (
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// nullableSchema returns the non-null part of a schema which also allows
+// null: "type": [T, "null"], or a oneOf or anyOf of {"type": "null"} and
+// another schema.
+func nullableSchema(schema *Schema) (*Schema, bool) {
+	if subschema, ok := unwrapNullableSchema(schema); ok {
+		return subschema, true
+	}
+	if len(schema.Type) < 2 {
+		return nil, false
+	}
+	var types TypeSet
+	for _, t := range schema.Type {
+		if t != TypeNull {
+			types = append(types, t)
+		}
+	}
+	// multi-type unions represent null themselves
+	if len(types) != 1 {
+		return nil, false
+	}
+	subschema := *schema
+	subschema.Type = types
+	// null is allowed by the pointer rather than the enum
+	if len(schema.Enum) > 0 {
+		subschema.Enum = nil
+		for _, v := range schema.Enum {
+			if v != nil {
+				subschema.Enum = append(subschema.Enum, v)
+			}
+		}
+	}
+	return &subschema, true
+}
+
+// nilable reports whether the zero value of a Go type is already encoded
+// as null, so that a nullable value needs no pointer.
+func (g *Generator) nilable(t jen.Code) bool {
+	s := g.goSource(t)
+	return strings.HasPrefix(s, "[]") || strings.HasPrefix(s, "map[") || strings.HasPrefix(s, "*") || s == "json.RawMessage"
+}
+
+// generateNullableType returns the type of a property which may be null:
+// a pointer, unless the type's zero value is already null, or with the
+// Nullable option a Nullable[T] if the property is optional, to tell an
+// absent property from null.
+func (g *Generator) generateNullableType(parent, propName string, schema *Schema, doc *document, required bool) jen.Code {
+	t := g.generateSchemaType(parent, propName, schema, doc, true)
+	if g.opts.Nullable && !required {
+		return doc.out.typeRef(g.generateNullableWrapper(doc.out)).Types(t)
+	}
+	if g.nilable(t) {
+		return t
+	}
+	return jen.Op("*").Add(t)
+}
+
+// generateNullableWrapper emits the generic Nullable type.
+func (g *Generator) generateNullableWrapper(out *output) string {
+	const name = "Nullable"
+	return g.declareType(name, "nullable", out, false, func() {
+		f := out.file
+		f.Comment("Nullable is an optional property which may be null. Its zero value is an")
+		f.Comment(`absent property, which is omitted by the "omitzero" JSON option.`)
+		f.Type().Id(name).Types(jen.Id("T").Any()).Struct(
+			jen.Id("Value").Id("T"),
+			jen.Comment("Null is set if the property is null."),
+			jen.Id("Null").Bool(),
+			jen.Comment("Present is set if the property is present, even if null."),
+			jen.Id("Present").Bool(),
+		).Line()
+
+		recv := jen.Id("v").Id(name).Types(jen.Id("T"))
+		f.Comment("IsZero reports whether the property is absent.")
+		f.Func().Params(recv.Clone()).Id("IsZero").Params().Bool().Block(
+			jen.Return(jen.Op("!").Id("v").Dot("Present")),
+		).Line()
+
+		f.Func().Params(recv.Clone()).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+			jen.If(jen.Id("v").Dot("Null").Op("||").Op("!").Id("v").Dot("Present")).Block(
+				jen.Return(jen.Index().Byte().Call(jen.Lit("null")), jen.Nil()),
+			),
+			jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("v").Dot("Value"))),
+		).Line()
+
+		f.Func().Params(jen.Id("v").Op("*").Id(name).Types(jen.Id("T"))).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+			jen.Op("*").Id("v").Op("=").Id(name).Types(jen.Id("T")).Values(jen.Dict{jen.Id("Present"): jen.True()}),
+			jen.If(jen.String().Call(jen.Id("b")).Op("==").Lit("null")).Block(
+				jen.Id("v").Dot("Null").Op("=").True(),
+				jen.Return(jen.Nil()),
+			),
+			jen.Return(jen.Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("v").Dot("Value"))),
+		).Line()
+	})
+}
+
+// multiTypeFields names the field of a multi-type union holding each type.
+var multiTypeFields = map[Type]string{
+	TypeBoolean: "Boolean",
+	TypeObject:  "Object",
+	TypeArray:   "Array",
+	TypeNumber:  "Number",
+	TypeString:  "String",
+	TypeInteger: "Integer",
+}
+
+// generateMultiType emits a tagged union for a schema with several types,
+// such as "type": ["string", "integer"]: a struct with a pointer field per
+// type, of which at most one is set, and null when none is.
+func (g *Generator) generateMultiType(name string, schema *Schema, doc *document, reuse bool) string {
+	var types []Type
+	var fields []jen.Code
+	fieldTypes := make(map[Type]jen.Code)
+	for _, t := range schema.Type {
+		if t == TypeNull {
+			continue
+		}
+		single := *schema
+		single.Type = TypeSet{t}
+		fieldTypes[t] = g.generateSchemaType(name, string(t), &single, doc, true)
+		types = append(types, t)
+		fields = append(fields, jen.Id(multiTypeFields[t]).Op("*").Add(fieldTypes[t]))
+	}
+	def := jen.Struct(fields...)
+
+	return g.declareType(name, "multi "+g.goSource(def), doc.out, reuse, func() {
+		f := doc.out.file
+		var names []string
+		for _, t := range types {
+			names = append(names, string(t))
+		}
+		typeComment(doc.out, schema)
+		if len(docLines(schema, "type")) > 0 {
+			f.Comment("//")
+		}
+		list := names[len(names)-1]
+		if len(names) > 1 {
+			list = strings.Join(names[:len(names)-1], ", ") + " or " + list
+		}
+		f.Commentf("%s holds a value of type %s. The field for its type is", name, list)
+		f.Comment("set, or none if the value is null.")
+		f.Type().Id(name).Add(def).Line()
+
+		var set []jen.Code
+		for _, t := range types {
+			field := multiTypeFields[t]
+			set = append(set, jen.If(jen.Id("v").Dot(field).Op("!=").Nil()).Block(
+				jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("v").Dot(field))),
+			))
+		}
+		f.Func().Params(jen.Id("v").Id(name)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+			append(set, jen.Return(jen.Index().Byte().Call(jen.Lit("null")), jen.Nil()))...,
+		).Line()
+
+		// the first byte of a JSON value tells its type, apart from
+		// integers and numbers
+		decode := func(t Type) jen.Code {
+			return jen.Return(jen.Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("v").Dot(multiTypeFields[t])))
+		}
+		var cases []jen.Code
+		for _, t := range types {
+			switch t {
+			case TypeString:
+				cases = append(cases, jen.Case(jen.LitRune('"')).Block(decode(t)))
+			case TypeBoolean:
+				cases = append(cases, jen.Case(jen.LitRune('t'), jen.LitRune('f')).Block(decode(t)))
+			case TypeArray:
+				cases = append(cases, jen.Case(jen.LitRune('[')).Block(decode(t)))
+			case TypeObject:
+				cases = append(cases, jen.Case(jen.LitRune('{')).Block(decode(t)))
+			}
+		}
+		cases = append(cases, jen.Case(jen.LitRune('n')).Block(jen.Return(jen.Nil())))
+		var numbers []jen.Code
+		if _, ok := fieldTypes[TypeInteger]; ok {
+			if _, ok := fieldTypes[TypeNumber]; ok {
+				numbers = append(numbers,
+					jen.Var().Id("i").Add(fieldTypes[TypeInteger]),
+					jen.If(jen.Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("i")).Op("==").Nil()).Block(
+						jen.Id("v").Dot("Integer").Op("=").Op("&").Id("i"),
+						jen.Return(jen.Nil()),
+					),
+					decode(TypeNumber),
+				)
+			} else {
+				numbers = append(numbers, decode(TypeInteger))
+			}
+		} else if _, ok := fieldTypes[TypeNumber]; ok {
+			numbers = append(numbers, decode(TypeNumber))
+		}
+
+		if len(numbers) == 0 {
+			numbers = append(numbers, jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("invalid "+name+" %s"), jen.Id("b"))))
+		}
+		cases = append(cases, jen.Default().Block(numbers...))
+		f.Func().Params(jen.Id("v").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+			jen.Op("*").Id("v").Op("=").Id(name).Values(),
+			jen.Switch(jen.Id("b").Index(jen.Lit(0))).Block(cases...),
+		).Line()
+	})
+}
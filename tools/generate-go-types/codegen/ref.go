@@ -0,0 +1,267 @@
+package codegen
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// output is a Go file being generated, along with the names of the types
+// already declared in it.
+type output struct {
+	file *jen.File
+	// path is the import path of the package, empty for the main output.
+	path string
+
+	// types maps every emitted type name to the Go source of its definition,
+	// and shapes maps that source back to the first name it was emitted
+	// under. Together they let nested types with the same shape share one
+	// definition and turn genuine name collisions into errors.
+	types  map[string]string
+	shapes map[string]string
+	// methods records "<Type>.<Method>" for methods declared outside of a
+	// type's own declaration.
+	methods map[string]bool
+}
+
+func newOutput(file *jen.File, path string) *output {
+	file.HeaderComment(generatedHeader)
+	return &output{
+		file:    file,
+		path:    path,
+		types:   make(map[string]string),
+		shapes:  make(map[string]string),
+		methods: make(map[string]bool),
+	}
+}
+
+// typeRef returns a reference to the type name declared in out.
+func (out *output) typeRef(name string) *jen.Statement {
+	if out.path == "" {
+		return jen.Id(name)
+	}
+	return jen.Qual(out.path, name)
+}
+
+// document is a loaded schema file.
+type document struct {
+	// base is the URI relative references are resolved against: the
+	// schema's $id if it has one, or else its file URL.
+	base *url.URL
+	// fileURL is the file the schema was loaded from.
+	fileURL *url.URL
+	// filename is the file the schema was loaded from, as given.
+	filename string
+	schema   *Schema
+	// raw is the decoded JSON, used to follow JSON pointers.
+	raw interface{}
+	// anchors maps every $anchor to the JSON pointer of its schema.
+	anchors map[string]string
+	out     *output
+}
+
+func (g *Generator) fileURL(filename string) *url.URL {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		g.failAt(filename, ErrLoad, "%v", err)
+	}
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+}
+
+// loadDocument loads a schema file and registers it under its file URL and
+// $id. Types for it are generated into out.
+func (g *Generator) loadDocument(filename string, out *output) *document {
+	u := g.fileURL(filename)
+	if doc, ok := g.documents[u.String()]; ok {
+		return doc
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		g.failAt(filename, ErrLoad, "%v", err)
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		g.failAt(filename, ErrLoad, "%v", err)
+	}
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		g.failAt(filename, ErrLoad, "%v", err)
+	}
+
+	doc := &document{
+		base:     u,
+		fileURL:  u,
+		filename: filename,
+		schema:   &schema,
+		raw:      raw,
+		anchors:  make(map[string]string),
+		out:      out,
+	}
+	if schema.ID != "" {
+		id, err := url.Parse(schema.ID)
+		if err != nil {
+			g.failAt(filename, ErrLoad, "invalid $id %q: %v", schema.ID, err)
+		}
+		doc.base = u.ResolveReference(id)
+		doc.base.Fragment = ""
+	}
+	collectAnchors(raw, "", doc.anchors)
+
+	g.documents[doc.fileURL.String()] = doc
+	g.documents[doc.base.String()] = doc
+	return doc
+}
+
+func collectAnchors(raw interface{}, pointer string, anchors map[string]string) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		if anchor, ok := v["$anchor"].(string); ok {
+			anchors[anchor] = pointer
+		}
+		for key, child := range v {
+			token := strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+			collectAnchors(child, pointer+"/"+token, anchors)
+		}
+	case []interface{}:
+		for i, child := range v {
+			collectAnchors(child, pointer+"/"+strconv.Itoa(i), anchors)
+		}
+	}
+}
+
+// lookupDocument finds the document a $ref points into. References are
+// resolved against the document's $id first; if nothing is loaded under
+// that URI, they are resolved against its file location and loaded from
+// disk, so relative file references work in schemas with an $id.
+func (g *Generator) lookupDocument(ref *url.URL, from *document) *document {
+	for _, base := range []*url.URL{from.base, from.fileURL} {
+		u := base.ResolveReference(ref)
+		u.Fragment = ""
+		if doc, ok := g.documents[u.String()]; ok {
+			return doc
+		}
+		if u.Scheme == "file" {
+			if _, err := os.Stat(filepath.FromSlash(u.Path)); err == nil {
+				return g.loadDocument(filepath.FromSlash(u.Path), g.sharedOutput())
+			}
+		}
+	}
+	g.fail(ErrInvalidRef, "%q: no schema loaded for %q", ref, from.base.ResolveReference(ref))
+	return nil
+}
+
+// resolveRef returns the schema a $ref points to, the document containing
+// it, a canonical key identifying it and a type name for it.
+func (g *Generator) resolveRef(ref string, from *document) (*Schema, *document, string, string) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		g.fail(ErrInvalidRef, "%q: %v", ref, err)
+	}
+	doc := from
+	if u.Scheme != "" || u.Host != "" || u.Path != "" {
+		doc = g.lookupDocument(u, from)
+	}
+
+	// anchors are resolved to the JSON pointer of the schema they name, so
+	// each schema is generated once however it is referenced
+	fragment := u.Fragment
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		pointer, ok := doc.anchors[fragment]
+		if !ok {
+			g.fail(ErrInvalidRef, "%q: no $anchor %q", ref, fragment)
+		}
+		fragment = pointer
+	}
+
+	var raw interface{}
+	var name string
+	if fragment == "" {
+		raw = doc.raw
+		name = doc.schema.Title
+		if name == "" {
+			name = strings.TrimSuffix(strings.TrimSuffix(path.Base(doc.fileURL.Path), ".json"), ".schema")
+		}
+	} else {
+		raw = g.followPointer(doc.raw, fragment, ref)
+		name = fragment[strings.LastIndex(fragment, "/")+1:]
+		name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		g.fail(ErrInvalidRef, "%q: %v", ref, err)
+	}
+	var schema Schema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		g.fail(ErrInvalidRef, "%q: %v", ref, err)
+	}
+	return &schema, doc, doc.base.String() + "#" + fragment, g.formatId(name)
+}
+
+// followPointer evaluates a JSON pointer against a decoded JSON document.
+func (g *Generator) followPointer(raw interface{}, pointer, ref string) interface{} {
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := raw.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				g.fail(ErrInvalidRef, "%q: no %q member", ref, token)
+			}
+			raw = child
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				g.fail(ErrInvalidRef, "%q: bad array index %q", ref, token)
+			}
+			raw = v[i]
+		default:
+			g.fail(ErrInvalidRef, "%q: cannot descend into %q", ref, token)
+		}
+	}
+	return raw
+}
+
+// generateRefType returns the type for the schema identified by key,
+// generating it into its document's output the first time it is seen.
+func (g *Generator) generateRefType(key, name string, schema *Schema, doc *document) *jen.Statement {
+	if t, ok := g.refTypes[key]; ok {
+		return t
+	}
+	// record the type first so recursive references terminate
+	g.refTypes[key] = doc.out.typeRef(name)
+	g.pending[key] = true
+	defer delete(g.pending, key)
+	defer g.in(doc, key[strings.Index(key, "#")+1:])()
+	g.generateDef(schema, doc, name)
+	return g.refTypes[key]
+}
+
+// generateDefs generates a type for every definition in doc.
+func (g *Generator) generateDefs(doc *document) {
+	var defNames []string
+	for d := range doc.schema.Defs {
+		defNames = append(defNames, d)
+	}
+	sort.Strings(defNames)
+	for _, d := range defNames {
+		elem := doc.schema.Defs[d]
+		g.generateRefType(doc.base.String()+"#/$defs/"+d, g.formatId(d), &elem, doc)
+	}
+}
+
+// sortDocuments sorts documents by their base URI.
+func sortDocuments(docs []*document) {
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].base.String() < docs[j].base.String()
+	})
+}
@@ -0,0 +1,559 @@
+package codegen
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tsOutput is a TypeScript module being generated.
+type tsOutput struct {
+	// path is the module's file name relative to the output directory, e.g.
+	// "order/created/v1.ts", used to import types from other modules.
+	path  string
+	decls []string
+	// types and shapes play the same role as in output.
+	types  map[string]string
+	shapes map[string]string
+	// imports maps a module specifier to the names imported from it.
+	imports map[string]map[string]bool
+}
+
+func newTSOutput(path string) *tsOutput {
+	return &tsOutput{
+		path:    path,
+		types:   make(map[string]string),
+		shapes:  make(map[string]string),
+		imports: make(map[string]map[string]bool),
+	}
+}
+
+// tsRef is a type generated for a $ref.
+type tsRef struct {
+	out  *tsOutput
+	name string
+}
+
+// tsOut returns the module types of doc are generated into.
+func (g *Generator) tsOut(doc *document) *tsOutput {
+	if out, ok := g.tsOutputs[doc]; ok {
+		return out
+	}
+	return g.tsShared
+}
+
+// tsTypeRef returns the name of a type for use in from, importing it if
+// it is declared in another module.
+func tsTypeRef(from *tsOutput, ref tsRef) string {
+	if ref.out != from {
+		rel, _ := strings.CutSuffix(relPath(path.Dir(from.path), ref.out.path), ".ts")
+		if !strings.HasPrefix(rel, ".") {
+			rel = "./" + rel
+		}
+		if from.imports[rel] == nil {
+			from.imports[rel] = make(map[string]bool)
+		}
+		from.imports[rel][ref.name] = true
+	}
+	return ref.name
+}
+
+// relPath returns target relative to the directory dir, both being
+// slash-separated paths relative to the same root.
+func relPath(dir, target string) string {
+	var up []string
+	for dir != "." && dir != "" && !strings.HasPrefix(target, dir+"/") {
+		up = append(up, "..")
+		dir = path.Dir(dir)
+	}
+	if dir != "." && dir != "" {
+		target = strings.TrimPrefix(target, dir+"/")
+	}
+	return path.Join(append(up, target)...)
+}
+
+// tsDeclare declares "export <kind> name <body>" in out, with the same
+// reuse and collision rules as declareType.
+func (g *Generator) tsDeclare(out *tsOutput, name, kind, body string, schema *Schema, reuse bool) string {
+	shape := kind + " " + body
+	if reuse {
+		if existing, ok := out.shapes[shape]; ok {
+			return existing
+		}
+	}
+	if existing, ok := out.types[name]; ok {
+		if existing != shape {
+			g.fail(ErrNameCollision, "type name %q is generated for two different schemas; set a distinct \"title\" on one of them", name)
+		}
+		return name
+	}
+	out.types[name] = shape
+	if _, ok := out.shapes[shape]; !ok {
+		out.shapes[shape] = name
+	}
+
+	decl := jsDoc(schema, "type", "")
+	if kind == "interface" {
+		decl += "export interface " + name + " " + body
+	} else {
+		decl += "export type " + name + " = " + body + ";"
+	}
+	out.decls = append(out.decls, decl)
+	return name
+}
+
+// jsDoc renders the annotations of a schema as a JSDoc comment, indented
+// by indent.
+func jsDoc(schema *Schema, what, indent string) string {
+	if schema == nil {
+		return ""
+	}
+	annotations := *schema
+	annotations.Deprecated = false
+	lines := docLines(&annotations, what)
+	if schema.Deprecated {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "@deprecated")
+	}
+	for i, line := range lines {
+		// descriptions and patterns must not end the comment
+		lines[i] = strings.ReplaceAll(line, "*/", "*\\/")
+	}
+	switch len(lines) {
+	case 0:
+		return ""
+	case 1:
+		return indent + "/** " + lines[0] + " */\n"
+	}
+	var b strings.Builder
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		if line == "" {
+			b.WriteString(indent + " *\n")
+		} else {
+			b.WriteString(indent + " * " + line + "\n")
+		}
+	}
+	b.WriteString(indent + " */\n")
+	return b.String()
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsPropertyName quotes property names which aren't identifiers.
+func tsPropertyName(propName string) string {
+	if tsIdentifier.MatchString(propName) {
+		return propName
+	}
+	return strconv.Quote(propName)
+}
+
+// tsArray returns the type of an array of t.
+func tsArray(t string) string {
+	if strings.ContainsAny(t, "|&") {
+		t = "(" + t + ")"
+	}
+	return t + "[]"
+}
+
+// tsLiterals returns the literal types of an enum or const, if its values
+// are all strings, numbers, booleans or null.
+func tsLiterals(schema *Schema) ([]string, bool) {
+	values := schema.Enum
+	if schema.Const != nil {
+		values = []interface{}{schema.Const}
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	var literals []string
+	for _, v := range values {
+		switch v.(type) {
+		case string, float64, bool, nil:
+			b, _ := json.Marshal(v)
+			literals = append(literals, string(b))
+		default:
+			return nil, false
+		}
+	}
+	return literals, true
+}
+
+// tsRefType returns the type for the schema identified by key, generating
+// it the first time it is seen.
+func (g *Generator) tsRefType(key, name string, schema *Schema, doc *document) tsRef {
+	if ref, ok := g.tsRefs[key]; ok {
+		return ref
+	}
+	g.tsRefs[key] = tsRef{out: g.tsOut(doc), name: name}
+	defer g.in(doc, key[strings.Index(key, "#")+1:])()
+	g.tsDef(schema, doc, name)
+	return g.tsRefs[key]
+}
+
+// tsNestedType is nestedType for TypeScript.
+func (g *Generator) tsNestedType(doc *document, generate func() string) string {
+	key := doc.base.String() + "#" + g.pointer
+	if ref, ok := g.tsRefs[key]; ok {
+		return tsTypeRef(g.tsOut(doc), ref)
+	}
+	ref := tsRef{out: g.tsOut(doc), name: generate()}
+	g.tsRefs[key] = ref
+	return ref.name
+}
+
+// tsDefs generates a type for every definition in doc.
+func (g *Generator) tsDefs(doc *document) {
+	var defNames []string
+	for d := range doc.schema.Defs {
+		defNames = append(defNames, d)
+	}
+	sort.Strings(defNames)
+	for _, d := range defNames {
+		elem := doc.schema.Defs[d]
+		g.tsRefType(doc.base.String()+"#/$defs/"+d, g.formatId(d), &elem, doc)
+	}
+}
+
+// tsDocument generates the root type of a schema document, named rootName,
+// and all of its definitions.
+func (g *Generator) tsDocument(doc *document, rootName string) {
+	defer g.in(doc, "")()
+	if doc.schema.Ref == "" {
+		g.tsRefs[doc.base.String()+"#"] = tsRef{out: g.tsOut(doc), name: rootName}
+		g.tsDef(doc.schema, doc, rootName)
+	}
+	g.tsDefs(doc)
+}
+
+// tsDef generates a named type for a root schema or definition.
+func (g *Generator) tsDef(schema *Schema, doc *document, name string) {
+	id := g.formatId(name)
+	scope := id
+	if schema == doc.schema {
+		scope = ""
+	}
+	switch {
+	case len(schema.OneOf) > 1 || len(schema.AnyOf) > 1:
+		g.tsUnion(id, scope, schema, doc, false)
+	case g.isAllOfObject(schema, doc):
+		g.tsInterface(id, scope, schema, doc, false)
+	case schemaType(schema) == TypeObject && len(schema.Properties) > 0:
+		g.tsInterface(id, scope, schema, doc, false)
+	default:
+		if literals, ok := tsLiterals(schema); ok {
+			g.tsDeclare(g.tsOut(doc), id, "type", strings.Join(literals, " | "), schema, false)
+			return
+		}
+		g.tsDeclare(g.tsOut(doc), id, "type", g.tsType(scope, name, schema, doc), schema, false)
+	}
+}
+
+// tsType returns the TypeScript type of a schema, declaring named types
+// for nested objects, enums and unions as generateSchemaType does.
+func (g *Generator) tsType(parent, propName string, schema *Schema, doc *document) string {
+	if schema == nil {
+		return "unknown"
+	}
+	if schema.IsFalse() {
+		return "never"
+	}
+	out := g.tsOut(doc)
+
+	if schema.Ref != "" {
+		target, targetDoc, key, name := g.resolveRef(schema.Ref, doc)
+		return tsTypeRef(out, g.tsRefType(key, name, target, targetDoc))
+	}
+
+	if subschema, ok := unwrapNullableSchema(schema); ok {
+		return g.tsType(parent, propName, subschema, doc) + " | null"
+	}
+
+	if len(schema.OneOf) > 1 || len(schema.AnyOf) > 1 {
+		return g.tsNestedType(doc, func() string {
+			name := g.nestedTypeName(parent, propName, schema)
+			return g.tsUnion(name, name, schema, doc, schema.Title == "")
+		})
+	}
+
+	if g.isAllOfObject(schema, doc) {
+		return g.tsNestedType(doc, func() string {
+			name := g.nestedTypeName(parent, propName, schema)
+			return g.tsInterface(name, name, schema, doc, schema.Title == "")
+		})
+	}
+
+	if literals, ok := tsLiterals(schema); ok {
+		if len(literals) == 1 {
+			return literals[0]
+		}
+		return g.tsNestedType(doc, func() string {
+			return g.tsDeclare(out, g.nestedTypeName(parent, propName, schema), "type", strings.Join(literals, " | "), schema, schema.Title == "")
+		})
+	}
+
+	if len(schema.Type) > 1 {
+		var types []string
+		for _, t := range schema.Type {
+			single := *schema
+			single.Type = TypeSet{t}
+			types = append(types, g.tsType(parent, propName, &single, doc))
+		}
+		return strings.Join(types, " | ")
+	}
+
+	switch schemaType(schema) {
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeNumber, TypeInteger:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeObject:
+		if len(schema.Properties) == 0 {
+			value := "unknown"
+			if ap := typedAdditionalProps(schema); ap != nil {
+				defer g.at("additionalProperties")()
+				value = g.tsType(parent, propName+"_value", ap, doc)
+			} else if len(schema.PatternProperties) == 1 {
+				for pattern, p := range schema.PatternProperties {
+					defer g.at("patternProperties", pattern)()
+					value = g.tsType(parent, propName+"_value", &p, doc)
+				}
+			}
+			return "Record<string, " + value + ">"
+		}
+		return g.tsNestedType(doc, func() string {
+			name := g.nestedTypeName(parent, propName, schema)
+			return g.tsInterface(name, name, schema, doc, schema.Title == "")
+		})
+	case TypeArray:
+		if len(schema.PrefixItems) > 0 {
+			return g.tsTuple(parent, propName, schema, doc)
+		}
+		defer g.at("items")()
+		return tsArray(g.tsType(parent, propName, schema.Items, doc))
+	default:
+		return "unknown"
+	}
+}
+
+// tsUnion declares a type alias for the union of the oneOf or anyOf
+// branches of schema. Object branches are named after the union and their
+// discriminator value, or else their position.
+func (g *Generator) tsUnion(name, scope string, schema *Schema, doc *document, reuse bool) string {
+	branches, keyword := schema.OneOf, "oneOf"
+	if len(branches) == 0 {
+		branches, keyword = schema.AnyOf, "anyOf"
+	}
+	u, discriminated := g.discriminatedUnion(schema, doc)
+
+	var types []string
+	for i := range branches {
+		restore := g.at(keyword, strconv.Itoa(i))
+		branchName := strconv.Itoa(i + 1)
+		if discriminated {
+			branchName = u.variants[i].value
+		}
+		types = append(types, g.tsType(name, branchName, &branches[i], doc))
+		restore()
+	}
+	return g.tsDeclare(g.tsOut(doc), name, "type", strings.Join(types, " | "), schema, reuse)
+}
+
+// tsInterface declares an interface for an object schema. The $ref
+// branches of an allOf become base interfaces, and the properties of its
+// other branches are merged in.
+func (g *Generator) tsInterface(name, scope string, schema *Schema, doc *document, reuse bool) string {
+	out := g.tsOut(doc)
+	props := make(map[string]Schema)
+	required := make(map[string]bool)
+	var extends []string
+	// inherited maps the properties of base interfaces to whether they
+	// are required there, and baseProps to their schemas
+	inherited := make(map[string]bool)
+	baseProps := make(map[string]Schema)
+
+	var merge func(schema *Schema)
+	merge = func(schema *Schema) {
+		for propName, prop := range schema.Properties {
+			if _, ok := props[propName]; !ok {
+				props[propName] = prop
+			}
+		}
+		for _, propName := range schema.Required {
+			required[propName] = true
+		}
+		for i := range schema.AllOf {
+			branch := &schema.AllOf[i]
+			if branch.Ref == "" {
+				merge(branch)
+				continue
+			}
+			restore := g.at("allOf", strconv.Itoa(i))
+			target, targetDoc, key, refName := g.resolveRef(branch.Ref, doc)
+			extends = append(extends, tsTypeRef(out, g.tsRefType(key, refName, target, targetDoc)))
+			restore()
+			refProps, baseRequired := g.collectProps(target, targetDoc)
+			for propName, prop := range refProps {
+				inherited[propName] = inherited[propName] || baseRequired[propName]
+				if _, ok := baseProps[propName]; !ok {
+					baseProps[propName] = prop
+				}
+			}
+		}
+	}
+	merge(schema)
+	// a branch which only requires an inherited property redeclares it
+	for propName := range required {
+		if _, ok := props[propName]; !ok {
+			if prop, ok := baseProps[propName]; ok {
+				props[propName] = prop
+			}
+		}
+	}
+
+	var propNames []string
+	for propName := range props {
+		// redeclaring an inherited property is only needed to make it
+		// required, and making it optional again is an error
+		if baseRequired, ok := inherited[propName]; ok && (baseRequired || !required[propName]) {
+			continue
+		}
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+
+	var b strings.Builder
+	if len(extends) > 0 {
+		b.WriteString("extends " + strings.Join(extends, ", ") + " ")
+	}
+	b.WriteString("{\n")
+	for _, propName := range propNames {
+		restore := g.at("properties", propName)
+		prop := props[propName]
+		t := g.tsType(scope, propName, &prop, doc)
+		restore()
+
+		b.WriteString(jsDoc(&prop, "property", "\t"))
+		b.WriteString("\t")
+		if prop.ReadOnly {
+			b.WriteString("readonly ")
+		}
+		b.WriteString(tsPropertyName(propName))
+		if !required[propName] {
+			b.WriteString("?")
+		}
+		b.WriteString(": " + t + ";\n")
+	}
+	if ap := typedAdditionalProps(schema); ap != nil {
+		// an index signature must admit the declared properties too
+		b.WriteString("\t[key: string]: unknown;\n")
+	}
+	b.WriteString("}")
+	return g.tsDeclare(out, name, "interface", b.String(), schema, reuse)
+}
+
+// tsSource renders a TypeScript module.
+func tsSource(out *tsOutput) []byte {
+	var b strings.Builder
+	b.WriteString("// " + generatedHeader + "\n\n")
+
+	var modules []string
+	for module := range out.imports {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		var names []string
+		for name := range out.imports[module] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString("import type { " + strings.Join(names, ", ") + " } from " + strconv.Quote(module) + ";\n")
+	}
+	if len(modules) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(strings.Join(out.decls, "\n\n"))
+	if len(out.decls) > 0 {
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// tsCatalog generates one module per event schema, e.g.
+// "order/created/v1.ts", and "common.ts" for the other schema files they
+// reference.
+func (g *Generator) tsCatalog(events []*catalogEvent) map[string][]byte {
+	g.tsShared = newTSOutput("common.ts")
+	for _, e := range events {
+		e.doc = g.loadDocument(e.filename, g.sharedOutput())
+		g.tsOutputs[e.doc] = newTSOutput(e.pkgDir + ".ts")
+	}
+	for _, e := range events {
+		if e.doc.schema.Ref == "" {
+			e.root = g.rootTypeName(e.doc.schema, e.pkgDir)
+		}
+		g.tsDocument(e.doc, e.root)
+	}
+
+	shared := make(map[*document]bool)
+	for _, d := range g.documents {
+		if _, ok := g.tsOutputs[d]; !ok {
+			shared[d] = true
+		}
+	}
+	var pending []*document
+	for d := range shared {
+		pending = append(pending, d)
+	}
+	sortDocuments(pending)
+	for _, d := range pending {
+		g.tsDefs(d)
+	}
+
+	files := make(map[string][]byte)
+	for _, e := range events {
+		files[e.pkgDir+".ts"] = tsSource(g.tsOutputs[e.doc])
+	}
+	if len(g.tsShared.decls) > 0 {
+		files["common.ts"] = tsSource(g.tsShared)
+	}
+	return files
+}
+
+// tsTuple returns a tuple type for an array with "prefixItems". Items past
+// minItems are optional, and items after the prefix items are a rest
+// element unless "items" is false.
+func (g *Generator) tsTuple(parent, propName string, schema *Schema, doc *document) string {
+	name := g.nestedTypeName(parent, propName, schema)
+	min, max := tupleBounds(schema)
+	var elems []string
+	for i := range schema.PrefixItems {
+		restore := g.at("prefixItems", strconv.Itoa(i))
+		t := g.tsType(name, "item"+strconv.Itoa(i), &schema.PrefixItems[i], doc)
+		if i >= min {
+			if strings.ContainsAny(t, "|&") {
+				t = "(" + t + ")"
+			}
+			t += "?"
+		}
+		elems = append(elems, t)
+		restore()
+	}
+	if max < 0 || max > len(schema.PrefixItems) {
+		restore := g.at("items")
+		elems = append(elems, "..."+tsArray(g.tsType(name, "rest", schema.Items, doc)))
+		restore()
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
@@ -0,0 +1,177 @@
+package codegen
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// union is a oneOf or anyOf whose branches are objects told apart by a
+// property with a different constant string value in each branch.
+type union struct {
+	// keyword is "oneOf" or "anyOf".
+	keyword       string
+	discriminator string
+	variants      []unionVariant
+}
+
+type unionVariant struct {
+	value  string
+	schema *Schema
+	doc    *document
+	// key and name are set for branches that are a $ref.
+	key, name string
+}
+
+// constString returns the single string value a schema is restricted to.
+func constString(schema *Schema) (string, bool) {
+	values, t, ok := enumValues(schema)
+	if !ok || t != TypeString || len(values) != 1 {
+		return "", false
+	}
+	return values[0].(string), true
+}
+
+// discriminatedUnion reports whether schema is a union of object schemas
+// with a discriminator property, resolving $ref branches.
+func (g *Generator) discriminatedUnion(schema *Schema, doc *document) (*union, bool) {
+	branches, keyword := schema.OneOf, "oneOf"
+	if len(branches) == 0 {
+		branches, keyword = schema.AnyOf, "anyOf"
+	}
+	if len(branches) < 2 {
+		return nil, false
+	}
+
+	variants := make([]unionVariant, len(branches))
+	for i := range branches {
+		v := unionVariant{schema: &branches[i], doc: doc}
+		if v.schema.Ref != "" {
+			v.schema, v.doc, v.key, v.name = g.resolveRef(v.schema.Ref, doc)
+			// methods can't be declared on types from another package
+			if v.doc.out != doc.out {
+				return nil, false
+			}
+		}
+		if len(v.schema.Properties) == 0 {
+			return nil, false
+		}
+		variants[i] = v
+	}
+
+	// candidates are properties with a constant value in every branch
+	var candidates []string
+	for propName := range variants[0].schema.Properties {
+		candidates = append(candidates, propName)
+	}
+	sort.Strings(candidates)
+	for _, propName := range candidates {
+		seen := make(map[string]bool)
+		for i := range variants {
+			prop, ok := variants[i].schema.Properties[propName]
+			if !ok {
+				break
+			}
+			value, ok := constString(&prop)
+			if !ok || seen[value] {
+				break
+			}
+			seen[value] = true
+			variants[i].value = value
+		}
+		if len(seen) == len(variants) {
+			return &union{keyword: keyword, discriminator: propName, variants: variants}, true
+		}
+	}
+	return nil, false
+}
+
+// generateUnion emits a sealed interface implemented by one struct per
+// variant, and a wrapper struct holding one of them which dispatches on
+// the discriminator when unmarshalling.
+func (g *Generator) generateUnion(name string, schema *Schema, u *union, doc *document, reuse bool) string {
+	out := doc.out
+	iface := name + "Variant"
+	marker := "is" + name
+
+	// generate variants before the union so their names are known
+	variantNames := make([]string, len(u.variants))
+	for i, v := range u.variants {
+		if v.key != "" {
+			g.generateRefType(v.key, v.name, v.schema, v.doc)
+			variantNames[i] = v.name
+		} else {
+			restore := g.at(u.keyword, strconv.Itoa(i))
+			variantName := g.nestedTypeName(name, v.value, v.schema)
+			variantNames[i] = g.generateStruct(variantName, variantName, v.schema, v.doc, false)
+			restore()
+		}
+	}
+
+	shape := "union " + u.discriminator + " " + strings.Join(variantNames, ",")
+	return g.declareType(name, shape, out, reuse, func() {
+		f := out.file
+
+		f.Commentf("%s is implemented by the variants of %s.", iface, name)
+		f.Type().Id(iface).Interface(jen.Id(marker).Params()).Line()
+
+		var cases []jen.Code
+		for i, v := range u.variants {
+			variant := variantNames[i]
+			prop := v.schema.Properties[u.discriminator]
+			discriminatorField := g.fieldName(u.discriminator, &prop)
+			f.Func().Params(jen.Id(variant)).Id(marker).Params().Block().Line()
+
+			// variants always marshal with their discriminator set
+			if !out.methods[variant+".MarshalJSON"] {
+				out.methods[variant+".MarshalJSON"] = true
+				f.Func().Params(jen.Id("v").Id(variant)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+					jen.Type().Id("raw").Id(variant),
+					jen.Id("r").Op(":=").Id("raw").Call(jen.Id("v")),
+					jen.Id("r").Dot(discriminatorField).Op("=").Lit(v.value),
+					jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("r"))),
+				).Line()
+			}
+
+			cases = append(cases, jen.Case(jen.Lit(v.value)).Block(
+				jen.Var().Id("v").Id(variant),
+				jen.If(
+					jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("v")),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Err())),
+				jen.Id("u").Dot("Value").Op("=").Id("v"),
+			))
+		}
+		cases = append(cases, jen.Default().Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("unknown "+name+" "+u.discriminator+" %q"), jen.Id("probe").Dot("Discriminator"))),
+		))
+
+		typeComment(out, schema)
+		if len(docLines(schema, "type")) > 0 {
+			f.Comment("//")
+		}
+		f.Commentf("%s holds one of the %s types, chosen by its %q property.", name, iface, u.discriminator)
+		f.Type().Id(name).Struct(jen.Id("Value").Id(iface)).Line()
+
+		f.Func().Params(jen.Id("u").Id(name)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+			jen.If(jen.Id("u").Dot("Value").Op("==").Nil()).Block(
+				jen.Return(jen.Index().Byte().Call(jen.Lit("null")), jen.Nil()),
+			),
+			jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("u").Dot("Value"))),
+		).Line()
+
+		f.Func().Params(jen.Id("u").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+			jen.Var().Id("probe").Struct(
+				jen.Id("Discriminator").String().Tag(map[string]string{"json": u.discriminator}),
+			),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("probe")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.Switch(jen.Id("probe").Dot("Discriminator")).Block(cases...),
+			jen.Return(jen.Nil()),
+		).Line()
+	})
+}
@@ -1,3 +1,7 @@
 module generate-index
 
 go 1.23
+
+require catalog v0.0.0
+
+replace catalog => ../catalog
@@ -1,90 +1,39 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
-	"sort"
-	"strings"
+
+	"catalog"
 )
 
 func main() {
 	const baseDir = "events"
-	const outputFile = "index.md"
-
-	// topLevel -> subject -> []versionLinks
-	grouped := make(map[string]map[string][]string)
-
-	err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() {
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".md") || !strings.HasPrefix(filepath.Base(path), "v") {
-			return nil
-		}
-
-		relPath := filepath.ToSlash(path)
-		subjectPath := strings.TrimPrefix(filepath.Dir(relPath), baseDir+"/")
-		subjectParts := strings.Split(subjectPath, "/")
-		if len(subjectParts) < 2 {
-			return nil // skip unexpected paths like ./events/foo/v1.md
-		}
-
-		topLevel := subjectParts[0]
-		subject := strings.Join(subjectParts, ".")
-
-		version := strings.TrimSuffix(filepath.Base(relPath), ".md")
-		link := fmt.Sprintf("[%s](./%s)", version, relPath)
-
-		if _, ok := grouped[topLevel]; !ok {
-			grouped[topLevel] = make(map[string][]string)
-		}
-		grouped[topLevel][subject] = append(grouped[topLevel][subject], link)
-
-		return nil
-	})
-
+	outputs := catalog.DefaultOutputs
+	maxDepth := flag.Int("max-depth", catalog.DefaultMaxDepth, "maximum number of tokens of a subject")
+	flag.StringVar(&outputs.Manifest, "manifest", outputs.Manifest, `path of the JSON manifest of the catalog, or "" to skip it`)
+	flag.BoolVar(&outputs.YAML, "yaml", false, "also write the manifest as YAML, next to the JSON manifest")
+	flag.StringVar(&outputs.Site, "site", "", "directory to write a static HTML site of the catalog to, if any")
+	flag.StringVar(&outputs.Graph, "graph", outputs.Graph, `path, without extension, of the dependency graph of services as Mermaid (.mmd) and DOT (.dot), or "" to skip it`)
+	flag.Parse()
+
+	events, err := catalog.Walk(baseDir, *maxDepth)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error walking events directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Build markdown
-	var b strings.Builder
-	b.WriteString("# Event Index\n\n")
-
-	topLevels := make([]string, 0, len(grouped))
-	for top := range grouped {
-		topLevels = append(topLevels, top)
+	for _, warning := range catalog.Warnings(events) {
+		fmt.Printf("⚠️  %s\n", warning)
 	}
-	sort.Strings(topLevels)
-
-	for _, top := range topLevels {
-		b.WriteString(fmt.Sprintf("## %s\n\n", top))
-		b.WriteString("| Subject | Versions |\n")
-		b.WriteString("|---------|----------|\n")
 
-		subjects := grouped[top]
-		subjectKeys := make([]string, 0, len(subjects))
-		for subj := range subjects {
-			subjectKeys = append(subjectKeys, subj)
-		}
-		sort.Strings(subjectKeys)
-
-		for _, subj := range subjectKeys {
-			links := subjects[subj]
-			sort.Strings(links)
-			b.WriteString(fmt.Sprintf("| `%s` | %s |\n", subj, strings.Join(links, ", ")))
-		}
-		b.WriteString("\n")
+	written, err := outputs.Write(events)
+	for _, path := range written {
+		fmt.Printf("✅ Generated %s\n", path)
 	}
-
-	err = os.WriteFile(outputFile, []byte(b.String()), 0644)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing index.md: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
 		os.Exit(1)
 	}
-
-	fmt.Println("✅ Generated index.md")
 }